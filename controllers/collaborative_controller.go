@@ -0,0 +1,294 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/database"
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/models"
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/utils"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var itemSimilarityCollection *mongo.Collection = database.OpenCollection("ItemSimilarity")
+
+// StartItemSimilarityJob periodically rebuilds the item-item similarity model used by the
+// collaborative recommendation path
+func StartItemSimilarityJob() {
+	interval := utils.GetEnvDuration("COLLAB_MODEL_INTERVAL", 6*time.Hour)
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			buildItemSimilarity()
+			<-ticker.C
+		}
+	}()
+}
+
+func buildItemSimilarity() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	interactions, err := loadInteractions(ctx, bson.M{})
+	if err != nil {
+		log.Printf("Item similarity build failed to load interactions: %v", err)
+		return
+	}
+
+	similarities := utils.BuildItemSimilarities(
+		interactions,
+		utils.GetEnvInt("COLLAB_NEIGHBORS", 30),
+		utils.GetEnvInt("COLLAB_MIN_COMMON_USERS", 2),
+		utils.GetEnvInt("COLLAB_MAX_ITEMS_PER_USER", 200),
+	)
+
+	// Replace every model row, then drop rows for movies that no longer have neighbours
+	builtAt := time.Now()
+	var writes []mongo.WriteModel
+	flush := func() bool {
+		if len(writes) == 0 {
+			return true
+		}
+		_, err := itemSimilarityCollection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+		writes = writes[:0]
+		if err != nil {
+			log.Printf("Item similarity build failed to store model: %v", err)
+			return false
+		}
+		return true
+	}
+
+	for imdbId, neighbors := range similarities {
+		row := models.ItemSimilarity{ImdbID: imdbId, UpdatedAt: builtAt}
+		for _, neighbor := range neighbors {
+			row.Neighbors = append(row.Neighbors, models.SimilarItem{ImdbID: neighbor.ID, Score: math.Round(neighbor.Score*10000) / 10000})
+		}
+		writes = append(writes, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"imdb_id": imdbId}).
+			SetReplacement(row).
+			SetUpsert(true))
+		if len(writes) >= 500 && !flush() {
+			return
+		}
+	}
+	if !flush() {
+		return
+	}
+
+	if _, err := itemSimilarityCollection.DeleteMany(ctx, bson.M{"updated_at": bson.M{"$lt": builtAt}}); err != nil {
+		log.Printf("Item similarity build failed to drop stale rows: %v", err)
+		return
+	}
+	log.Printf("Item similarity model rebuilt: %d movies from %d users", len(similarities), len(interactions))
+}
+
+// loadInteractions returns how strongly each user engaged with each movie (user ID -> IMDb ID -> weight
+// in 0..1). An explicit rating overrides watch time: ratings of 4 or lower count as no interest,
+// while watch time saturates at COLLAB_FULL_WATCH. filter narrows both sources, e.g. to one user.
+func loadInteractions(ctx context.Context, filter bson.M) (map[string]map[string]float64, error) {
+	interactions := map[string]map[string]float64{}
+	set := func(userId, imdbId string, weight float64) {
+		if interactions[userId] == nil {
+			interactions[userId] = map[string]float64{}
+		}
+		interactions[userId][imdbId] = weight
+	}
+
+	// Watch time per user and title
+	fullWatch := utils.GetEnvDuration("COLLAB_FULL_WATCH", 20*time.Minute).Seconds()
+	if fullWatch <= 0 {
+		fullWatch = 1
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$group", Value: bson.M{
+			"_id":     bson.M{"user_id": "$user_id", "imdb_id": "$imdb_id"},
+			"watched": bson.M{"$sum": "$watched_seconds"},
+		}}},
+	}
+	cursor, err := historyCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var watched []struct {
+		ID struct {
+			UserID string `bson:"user_id"`
+			ImdbID string `bson:"imdb_id"`
+		} `bson:"_id"`
+		Watched float64 `bson:"watched"`
+	}
+	if err = cursor.All(ctx, &watched); err != nil {
+		return nil, err
+	}
+	for _, row := range watched {
+		set(row.ID.UserID, row.ID.ImdbID, math.Min(row.Watched/fullWatch, 1))
+	}
+
+	// Ratings count even while the review text waits for moderation; rejected reviews are ignored
+	reviewFilter := bson.M{"status": bson.M{"$ne": "rejected"}}
+	for key, value := range filter {
+		reviewFilter[key] = value
+	}
+	opts := options.Find().SetProjection(bson.M{"user_id": 1, "imdb_id": 1, "rating": 1})
+	cursor, err = reviewCollection.Find(ctx, reviewFilter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var reviews []models.UserReview
+	if err = cursor.All(ctx, &reviews); err != nil {
+		return nil, err
+	}
+	for _, review := range reviews {
+		set(review.UserID, review.ImdbID, math.Max(float64(review.Rating-4)/6, 0))
+	}
+
+	return interactions, nil
+}
+
+// collaborativeRecommendations scores unwatched movies by their similarity to what the user
+// watched, liked or asked for more of, blended with the share of each movie's genres the user
+// likes. Dismissed movies are never returned. It returns nothing when the user has no usable
+// signals or the model has no neighbours for them (cold start).
+func collaborativeRecommendations(ctx context.Context, userId string, genreNames []string, feedback recommendationFeedback, limit int) ([]models.RecommendedMovie, error) {
+	interactions, err := loadInteractions(ctx, bson.M{"user_id": userId})
+	if err != nil {
+		return nil, err
+	}
+	seen := interactions[userId]
+
+	seeds := map[string]float64{}
+	for imdbId, weight := range seen {
+		if weight > 0 {
+			seeds[imdbId] = weight
+		}
+	}
+	boosted := map[string]bool{}
+	boost := utils.GetEnvFloat("RECOMMEND_FEEDBACK_BOOST", 1)
+	for _, imdbId := range feedback.boosted {
+		boosted[imdbId] = true
+		seeds[imdbId] = math.Max(seeds[imdbId], boost)
+	}
+	if len(seeds) == 0 {
+		return nil, nil
+	}
+
+	seedIds := make([]string, 0, len(seeds))
+	for imdbId := range seeds {
+		seedIds = append(seedIds, imdbId)
+	}
+	cursor, err := itemSimilarityCollection.Find(ctx, bson.M{"imdb_id": bson.M{"$in": seedIds}})
+	if err != nil {
+		return nil, err
+	}
+	var rows []models.ItemSimilarity
+	if err = cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	// Anything the user has watched or rated is excluded, even if they did not like it.
+	// The seed contributing most to a candidate becomes its "similar to" reason.
+	scores := map[string]float64{}
+	sources := map[string]utils.ScoredItem{}
+	for _, row := range rows {
+		for _, neighbor := range row.Neighbors {
+			if _, watched := seen[neighbor.ImdbID]; watched || feedback.suppressed[neighbor.ImdbID] {
+				continue
+			}
+			contribution := seeds[row.ImdbID] * neighbor.Score
+			scores[neighbor.ImdbID] += contribution
+			if contribution > sources[neighbor.ImdbID].Score {
+				sources[neighbor.ImdbID] = utils.ScoredItem{ID: row.ImdbID, Score: contribution}
+			}
+		}
+	}
+	if len(scores) == 0 {
+		return nil, nil
+	}
+
+	candidates := make([]utils.ScoredItem, 0, len(scores))
+	maxScore := 0.0
+	for imdbId, score := range scores {
+		candidates = append(candidates, utils.ScoredItem{ID: imdbId, Score: score})
+		maxScore = math.Max(maxScore, score)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+	if maxCandidates := utils.GetEnvInt("COLLAB_CANDIDATE_LIMIT", 100); len(candidates) > maxCandidates {
+		candidates = candidates[:maxCandidates]
+	}
+
+	ids := make([]string, 0, len(candidates)*2)
+	for _, candidate := range candidates {
+		ids = append(ids, candidate.ID, sources[candidate.ID].ID)
+	}
+	movies, err := findMoviesByImdbIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	favourite := map[string]bool{}
+	for _, name := range genreNames {
+		favourite[name] = true
+	}
+	collabWeight := math.Min(math.Max(utils.GetEnvFloat("RECOMMEND_COLLAB_WEIGHT", 0.7), 0), 1)
+
+	type blendedMovie struct {
+		recommended models.RecommendedMovie
+		score       float64
+	}
+	blended := make([]blendedMovie, 0, len(candidates))
+	for _, candidate := range candidates {
+		movie, ok := movies[candidate.ID]
+		if !ok {
+			continue
+		}
+		genreMatch := 0.0
+		if len(movie.Genre) > 0 {
+			matched := 0
+			for _, genre := range movie.Genre {
+				if favourite[genre.GenreName] {
+					matched++
+				}
+			}
+			genreMatch = float64(matched) / float64(len(movie.Genre))
+		}
+		collabScore := collabWeight * candidate.Score / maxScore
+		genreScore := (1 - collabWeight) * genreMatch
+
+		// Explain by whichever side of the blend contributed more
+		reason, hasGenre := genreReason(movie, favourite)
+		source, hasSource := movies[sources[candidate.ID].ID]
+		if hasSource && (!hasGenre || collabScore >= genreScore) {
+			reason = similarReason(source, boosted[source.ImdbID])
+		}
+
+		blended = append(blended, blendedMovie{
+			recommended: models.RecommendedMovie{Movie: movie, Reason: reason},
+			score:       collabScore + genreScore,
+		})
+	}
+
+	sort.SliceStable(blended, func(i, j int) bool {
+		return blended[i].score > blended[j].score
+	})
+	if len(blended) > limit {
+		blended = blended[:limit]
+	}
+
+	recommended := make([]models.RecommendedMovie, len(blended))
+	for i, entry := range blended {
+		recommended[i] = entry.recommended
+	}
+	return recommended, nil
+}
@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/models"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// anyVersion is what ifMatchVersion returns for "If-Match: *"
+const anyVersion = -1
+
+// movieETag formats a movie's version as a strong entity tag
+func movieETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// ifMatchVersion reads the movie version the client last saw from the If-Match header, answering
+// the request itself with 428 when the header is missing or is not a movie ETag
+func ifMatchVersion(c *gin.Context) (int, bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		c.JSON(http.StatusPreconditionRequired, gin.H{"error": "If-Match header required, use the ETag of the movie"})
+		return 0, false
+	}
+	if header == "*" {
+		return anyVersion, true
+	}
+	version, err := strconv.Atoi(strings.Trim(header, `"`))
+	if err != nil || version < 0 || !strings.HasPrefix(header, `"`) || !strings.HasSuffix(header, `"`) {
+		c.JSON(http.StatusPreconditionRequired, gin.H{"error": "If-Match must be the ETag of the movie"})
+		return 0, false
+	}
+	return version, true
+}
+
+// withVersion restricts a movie filter to one version. Movies saved before versioning count as version 0.
+func withVersion(filter bson.M, version int) bson.M {
+	if version == anyVersion {
+		return filter
+	}
+	versioned := bson.M{"version": version}
+	if version == 0 {
+		versioned["version"] = bson.M{"$in": bson.A{nil, 0}}
+	}
+	for key, value := range filter {
+		versioned[key] = value
+	}
+	return versioned
+}
+
+// bumpVersion adds the version increment to a movie update so it lands atomically with the change
+func bumpVersion(update bson.M) bson.M {
+	bumped := bson.M{"$inc": bson.M{"version": 1}}
+	for key, value := range update {
+		bumped[key] = value
+	}
+	return bumped
+}
+
+// matchLoadedVersion checks the If-Match version against a movie the handler has already loaded,
+// answering 412 on a mismatch. The update that follows should be pinned to movie.Version so the
+// movie cannot change between the check and the write.
+func matchLoadedVersion(c *gin.Context, movie models.Movie, version int) bool {
+	if version == anyVersion || version == movie.Version {
+		return true
+	}
+	c.Header("ETag", movieETag(movie.Version))
+	c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Movie was changed by someone else, reload it and try again"})
+	return false
+}
+
+// updateMovieIfMatch is updateMovie for a movie at the version given by ifMatchVersion. It answers
+// the request itself on failure: 404 when no movie matches filter, 412 with the current ETag when the
+// movie changed since the client read it. On success it sets the new ETag.
+func updateMovieIfMatch(ctx context.Context, c *gin.Context, filter, update bson.M, version int, revision models.MovieRevision) (models.Movie, bool) {
+	updated, err := updateMovie(ctx, withVersion(filter, version), update, revision)
+	if err == nil {
+		c.Header("ETag", movieETag(updated.Version))
+		return updated, true
+	}
+	if err != mongo.ErrNoDocuments {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update movie"})
+		return models.Movie{}, false
+	}
+
+	var current models.Movie
+	if err := movieCollection.FindOne(ctx, filter).Decode(&current); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+			return models.Movie{}, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return models.Movie{}, false
+	}
+	c.Header("ETag", movieETag(current.Version))
+	c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Movie was changed by someone else, reload it and try again"})
+	return models.Movie{}, false
+}
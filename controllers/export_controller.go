@@ -0,0 +1,215 @@
+package controllers
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/models"
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// exportCSVColumns match the bulk import columns so a CSV export can be imported again
+var exportCSVColumns = []string{"imdb_id", "title", "poster_path", "youtube_id", "genres", "keywords", "admin_review", "year", "plot", "runtime_minutes"}
+
+// ExportMovies streams the catalog straight from the database cursor as format=ndjson|csv|json,
+// with the same filters as GET /movies, leaving out movies in the trash. gzip=true compresses the download.
+func ExportMovies() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		format := c.DefaultQuery("format", "ndjson")
+		contentTypes := map[string]string{
+			"ndjson": "application/x-ndjson",
+			"csv":    "text/csv; charset=utf-8",
+			"json":   "application/json",
+		}
+		contentType, ok := contentTypes[format]
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "format must be ndjson, csv or json"})
+			return
+		}
+		compress := c.Query("gzip") == "true"
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), utils.GetEnvDuration("EXPORT_TIMEOUT", 30*time.Minute))
+		defer cancel()
+
+		filter, status, err := movieListFilter(ctx, c)
+		if err != nil {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		// Exported rows carry no deletion marker, so trashed movies would come back live on re-import
+		filter = liveMovieFilter(filter)
+
+		opts := options.Find().SetSort(bson.D{{Key: "imdb_id", Value: 1}}).SetBatchSize(int32(utils.GetEnvInt("EXPORT_BATCH_SIZE", 500)))
+		cursor, err := movieCollection.Find(ctx, filter, opts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		defer cursor.Close(ctx)
+
+		// Headers are committed from here on; a failure can only cut the stream short
+		filename := "movies-" + time.Now().UTC().Format("20060102") + "." + format
+		var out io.Writer = c.Writer
+		var gz *gzip.Writer
+		if compress {
+			filename += ".gz"
+			c.Header("Content-Type", "application/gzip")
+			gz = gzip.NewWriter(c.Writer)
+			defer gz.Close()
+			out = gz
+		} else {
+			c.Header("Content-Type", contentType)
+		}
+		c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+		c.Status(http.StatusOK)
+
+		writer := newMovieExportWriter(format, out)
+		count := 0
+		for cursor.Next(ctx) {
+			var movie models.Movie
+			if err := cursor.Decode(&movie); err != nil {
+				log.Printf("Export stopped after %d movies: %v", count, err)
+				return
+			}
+			if err := writer.write(movie); err != nil {
+				log.Printf("Export stopped after %d movies: %v", count, err)
+				return
+			}
+			count++
+
+			// Push data to the client regularly instead of letting the response buffer grow
+			if count%500 == 0 {
+				if err := writer.flush(); err != nil {
+					log.Printf("Export stopped after %d movies: %v", count, err)
+					return
+				}
+				if gz != nil {
+					if err := gz.Flush(); err != nil {
+						log.Printf("Export stopped after %d movies: %v", count, err)
+						return
+					}
+				}
+				c.Writer.Flush()
+			}
+		}
+		if err := cursor.Err(); err != nil {
+			log.Printf("Export stopped after %d movies: %v", count, err)
+			return
+		}
+		if err := writer.close(); err != nil {
+			log.Printf("Export failed to finish after %d movies: %v", count, err)
+		}
+	}
+}
+
+// movieExportWriter encodes movies one at a time in the export format
+type movieExportWriter struct {
+	format  string
+	out     io.Writer
+	csv     *csv.Writer
+	written int
+}
+
+func newMovieExportWriter(format string, out io.Writer) *movieExportWriter {
+	return &movieExportWriter{format: format, out: out}
+}
+
+func (w *movieExportWriter) write(movie models.Movie) error {
+	switch w.format {
+	case "csv":
+		if w.csv == nil {
+			w.csv = csv.NewWriter(w.out)
+			if err := w.csv.Write(exportCSVColumns); err != nil {
+				return err
+			}
+		}
+		genres := make([]string, len(movie.Genre))
+		for i, genre := range movie.Genre {
+			genres[i] = genre.GenreName
+		}
+		adminReview := ""
+		if movie.AdminReview != nil {
+			adminReview = *movie.AdminReview
+		}
+		year, runtime := "", ""
+		if movie.Year > 0 {
+			year = strconv.Itoa(movie.Year)
+		}
+		if movie.RuntimeMinutes > 0 {
+			runtime = strconv.Itoa(movie.RuntimeMinutes)
+		}
+		w.written++
+		return w.csv.Write([]string{
+			movie.ImdbID, movie.Title, movie.PosterPath, movie.YouTubeID,
+			strings.Join(genres, "|"), strings.Join(movie.Keywords, "|"),
+			adminReview, year, movie.Plot, runtime,
+		})
+	case "json":
+		separator := ","
+		if w.written == 0 {
+			separator = "["
+		}
+		if _, err := io.WriteString(w.out, separator); err != nil {
+			return err
+		}
+		w.written++
+		return writeJSON(w.out, movie)
+	default:
+		w.written++
+		if err := writeJSON(w.out, movie); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w.out, "\n")
+		return err
+	}
+}
+
+func (w *movieExportWriter) flush() error {
+	if w.csv != nil {
+		w.csv.Flush()
+		return w.csv.Error()
+	}
+	return nil
+}
+
+// close finishes the document: the JSON array is terminated and an empty CSV still gets its header
+func (w *movieExportWriter) close() error {
+	switch w.format {
+	case "csv":
+		if w.csv == nil {
+			w.csv = csv.NewWriter(w.out)
+			if err := w.csv.Write(exportCSVColumns); err != nil {
+				return err
+			}
+		}
+		return w.flush()
+	case "json":
+		closing := "]"
+		if w.written == 0 {
+			closing = "[]"
+		}
+		_, err := io.WriteString(w.out, closing)
+		return err
+	}
+	return nil
+}
+
+func writeJSON(out io.Writer, movie models.Movie) error {
+	data, err := json.Marshal(movie)
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(data)
+	return err
+}
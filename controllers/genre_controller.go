@@ -0,0 +1,470 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/database"
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/models"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var genreCollection *mongo.Collection = database.OpenCollection("Genre")
+
+// genreCatalog indexes the genre collection by ID and by normalized name and alias
+type genreCatalog struct {
+	byID  map[int]models.GenreDefinition
+	byKey map[string]models.GenreDefinition
+}
+
+// genreKey normalizes a genre name for matching: "Sci-Fi", "sci fi" and "SCIFI" share a key
+func genreKey(name string) string {
+	var key strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			key.WriteRune(r)
+		}
+	}
+	return key.String()
+}
+
+func loadGenreCatalog(ctx context.Context) (genreCatalog, error) {
+	catalog := genreCatalog{byID: map[int]models.GenreDefinition{}, byKey: map[string]models.GenreDefinition{}}
+
+	cursor, err := genreCollection.Find(ctx, bson.M{})
+	if err != nil {
+		return catalog, err
+	}
+	var genres []models.GenreDefinition
+	if err = cursor.All(ctx, &genres); err != nil {
+		return catalog, err
+	}
+
+	for _, genre := range genres {
+		catalog.byID[genre.GenreID] = genre
+		catalog.byKey[genreKey(genre.Name)] = genre
+		for _, alias := range genre.Aliases {
+			catalog.byKey[genreKey(alias)] = genre
+		}
+	}
+	return catalog, nil
+}
+
+// lookup resolves a genre ID, name or alias
+func (catalog genreCatalog) lookup(value string) (models.GenreDefinition, bool) {
+	if id, err := strconv.Atoi(value); err == nil {
+		genre, ok := catalog.byID[id]
+		return genre, ok
+	}
+	genre, ok := catalog.byKey[genreKey(value)]
+	return genre, ok
+}
+
+// withDescendants returns id followed by the IDs of all of its sub-genres
+func (catalog genreCatalog) withDescendants(id int) []int {
+	children := map[int][]int{}
+	for _, genre := range catalog.byID {
+		if genre.ParentID != nil {
+			children[*genre.ParentID] = append(children[*genre.ParentID], genre.GenreID)
+		}
+	}
+
+	ids := []int{id}
+	seen := map[int]bool{id: true}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range children[ids[i]] {
+			if !seen[child] {
+				seen[child] = true
+				ids = append(ids, child)
+			}
+		}
+	}
+	return ids
+}
+
+// canonicalGenres replaces client-supplied genres with their catalog entries, dropping duplicates.
+// unknown lists the genre IDs that are not in the catalog.
+func canonicalGenres(ctx context.Context, genres []models.Genre) (canonical []models.Genre, unknown []int, err error) {
+	catalog, err := loadGenreCatalog(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	canonical = make([]models.Genre, 0, len(genres))
+	seen := map[int]bool{}
+	for _, genre := range genres {
+		definition, ok := catalog.byID[genre.GenreID]
+		if !ok {
+			unknown = append(unknown, genre.GenreID)
+			continue
+		}
+		if !seen[definition.GenreID] {
+			seen[definition.GenreID] = true
+			canonical = append(canonical, models.Genre{GenreID: definition.GenreID, GenreName: definition.Name})
+		}
+	}
+	return canonical, unknown, nil
+}
+
+// GetGenres lists the genre catalog
+func GetGenres() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+		cursor, err := genreCollection.Find(ctx, bson.M{}, opts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		genres := []models.GenreDefinition{}
+		if err = cursor.All(ctx, &genres); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"genres": genres, "total_found": len(genres)})
+	}
+}
+
+// CreateGenre adds a genre to the catalog
+func CreateGenre() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var genre models.GenreDefinition
+		if err := c.ShouldBindJSON(&genre); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
+			return
+		}
+		if err := movieValidate.Struct(genre); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		catalog, err := loadGenreCatalog(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if _, exists := catalog.byID[genre.GenreID]; exists {
+			c.JSON(http.StatusConflict, gin.H{"error": "Genre ID already exists"})
+			return
+		}
+		if err := checkGenreDefinition(catalog, genre); err != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+
+		genre.ID = bson.NewObjectID()
+		genre.CreatedAt = time.Now()
+		genre.UpdatedAt = genre.CreatedAt
+		if _, err := genreCollection.InsertOne(ctx, genre); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusCreated, genre)
+	}
+}
+
+// UpdateGenre edits a genre and renames it everywhere it is embedded
+func UpdateGenre() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		genreId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid genre ID"})
+			return
+		}
+
+		var genre models.GenreDefinition
+		if err := c.ShouldBindJSON(&genre); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
+			return
+		}
+		genre.GenreID = genreId
+		if err := movieValidate.Struct(genre); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		catalog, err := loadGenreCatalog(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if _, exists := catalog.byID[genreId]; !exists {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Genre not found"})
+			return
+		}
+		if err := checkGenreDefinition(catalog, genre); err != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+
+		update := bson.M{"$set": bson.M{
+			"name":       genre.Name,
+			"aliases":    genre.Aliases,
+			"parent_id":  genre.ParentID,
+			"updated_at": time.Now(),
+		}}
+		opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+		var updated models.GenreDefinition
+		if err := genreCollection.FindOneAndUpdate(ctx, bson.M{"genre_id": genreId}, update, opts).Decode(&updated); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := renameEmbeddedGenre(ctx, genreId, genre.Name); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Genre updated but renaming it on movies and users failed"})
+			return
+		}
+
+		c.JSON(http.StatusOK, updated)
+	}
+}
+
+// DeleteGenre removes a genre that no sub-genre, movie, series or user refers to
+func DeleteGenre() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		genreId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid genre ID"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		references := []struct {
+			collection *mongo.Collection
+			filter     bson.M
+		}{
+			{genreCollection, bson.M{"parent_id": genreId}},
+			{movieCollection, bson.M{"genre.genre_id": genreId}},
+			{seriesCollection, bson.M{"genre.genre_id": genreId}},
+			{userCollection, bson.M{"favourite_genres.genre_id": genreId}},
+		}
+		for _, reference := range references {
+			count, err := reference.collection.CountDocuments(ctx, reference.filter)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			if count > 0 {
+				c.JSON(http.StatusConflict, gin.H{"error": "Genre is still in use"})
+				return
+			}
+		}
+
+		result, err := genreCollection.DeleteOne(ctx, bson.M{"genre_id": genreId})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if result.DeletedCount == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Genre not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Genre deleted"})
+	}
+}
+
+// MigrateGenres rewrites the genres embedded in movies, series and users to catalog entries,
+// matching them by name or alias. Genres that match nothing are left untouched and reported
+// so an admin can add the missing genre or alias and run the migration again.
+func MigrateGenres() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		dryRun := c.Query("dry_run") == "true"
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+
+		catalog, err := loadGenreCatalog(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		result := models.GenreMigrationResult{DryRun: dryRun}
+		unresolved := map[string]bool{}
+		targets := []struct {
+			collection *mongo.Collection
+			field      string
+			updated    *int
+		}{
+			{movieCollection, "genre", &result.MoviesUpdated},
+			{seriesCollection, "genre", &result.SeriesUpdated},
+			{userCollection, "favourite_genres", &result.UsersUpdated},
+		}
+		for _, target := range targets {
+			updated, err := migrateEmbeddedGenres(ctx, catalog, target.collection, target.field, dryRun, unresolved)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "result": result})
+				return
+			}
+			*target.updated = updated
+		}
+
+		result.Unresolved = make([]string, 0, len(unresolved))
+		for name := range unresolved {
+			result.Unresolved = append(result.Unresolved, name)
+		}
+		sort.Strings(result.Unresolved)
+
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// migrateEmbeddedGenres rewrites a collection's embedded genres onto the catalog. Like genre
+// renames, this is catalog maintenance rather than an edit of the movie, so it records no movie
+// revision and leaves the version alone.
+func migrateEmbeddedGenres(ctx context.Context, catalog genreCatalog, collection *mongo.Collection, field string, dryRun bool, unresolved map[string]bool) (int, error) {
+	cursor, err := collection.Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{field: 1}))
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	updated := 0
+	var writes []mongo.WriteModel
+	flush := func() error {
+		if len(writes) == 0 || dryRun {
+			writes = writes[:0]
+			return nil
+		}
+		_, err := collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+		writes = writes[:0]
+		return err
+	}
+
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID bson.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return updated, err
+		}
+		// Documents without the field have nothing to migrate
+		var genres []models.Genre
+		if err := cursor.Current.Lookup(field).Unmarshal(&genres); err != nil {
+			continue
+		}
+
+		normalized := make([]models.Genre, 0, len(genres))
+		seen := map[int]bool{}
+		changed := false
+		for _, genre := range genres {
+			definition, ok := catalog.byKey[genreKey(genre.GenreName)]
+			if !ok {
+				unresolved[genre.GenreName] = true
+				normalized = append(normalized, genre)
+				continue
+			}
+			if seen[definition.GenreID] {
+				changed = true
+				continue
+			}
+			seen[definition.GenreID] = true
+			if genre.GenreID != definition.GenreID || genre.GenreName != definition.Name {
+				changed = true
+			}
+			normalized = append(normalized, models.Genre{GenreID: definition.GenreID, GenreName: definition.Name})
+		}
+		if !changed {
+			continue
+		}
+
+		updated++
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": doc.ID}).
+			SetUpdate(bson.M{"$set": bson.M{field: normalized}}))
+		if len(writes) >= 500 {
+			if err := flush(); err != nil {
+				return updated, err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return updated, err
+	}
+	return updated, flush()
+}
+
+// checkGenreDefinition rejects a name or alias already used by another genre, and a parent
+// that does not exist or would create a cycle
+func checkGenreDefinition(catalog genreCatalog, genre models.GenreDefinition) error {
+	for _, name := range append([]string{genre.Name}, genre.Aliases...) {
+		if existing, taken := catalog.byKey[genreKey(name)]; taken && existing.GenreID != genre.GenreID {
+			return errors.New("\"" + name + "\" is already used by genre " + existing.Name)
+		}
+	}
+
+	if genre.ParentID == nil {
+		return nil
+	}
+	if _, exists := catalog.byID[*genre.ParentID]; !exists {
+		return errors.New("Parent genre not found")
+	}
+	for _, descendant := range catalog.withDescendants(genre.GenreID) {
+		if descendant == *genre.ParentID {
+			return errors.New("A genre cannot be nested under itself or its sub-genres")
+		}
+	}
+	return nil
+}
+
+// renameEmbeddedGenre keeps genre_name in sync with the catalog wherever the genre is embedded.
+// The genre ID does not change, so movies get no revision for it.
+func renameEmbeddedGenre(ctx context.Context, genreId int, name string) error {
+	targets := []struct {
+		collection *mongo.Collection
+		field      string
+	}{
+		{movieCollection, "genre"},
+		{seriesCollection, "genre"},
+		{userCollection, "favourite_genres"},
+	}
+	for _, target := range targets {
+		filter := bson.M{target.field + ".genre_id": genreId}
+		update := bson.M{"$set": bson.M{target.field + ".$[g].genre_name": name}}
+		opts := options.UpdateMany().SetArrayFilters([]any{bson.M{"g.genre_id": genreId}})
+		if _, err := target.collection.UpdateMany(ctx, filter, update, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// requireCatalogGenres resolves a request's genres against the catalog, answering 400 for
+// unknown genre IDs. ok is false when a response has already been written.
+func requireCatalogGenres(c *gin.Context, genres []models.Genre) (canonical []models.Genre, ok bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	canonical, unknown, err := canonicalGenres(ctx, genres)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to look up genres"})
+		return nil, false
+	}
+	if len(unknown) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown genre IDs", "genre_ids": unknown})
+		return nil, false
+	}
+	return canonical, true
+}
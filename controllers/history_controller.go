@@ -0,0 +1,250 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/database"
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/models"
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var historyCollection *mongo.Collection = database.OpenCollection("WatchHistory")
+
+// recordHistory folds flushed progress updates into viewing sessions. Watched time is the
+// forward movement of the position, capped by the wall-clock time since the last report so
+// seeking ahead is not counted as watching.
+func recordHistory(batch map[string]models.WatchProgress) {
+	sessionGap := utils.GetEnvDuration("HISTORY_SESSION_GAP", 30*time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	for _, progress := range batch {
+		filter := bson.M{
+			"user_id":         progress.UserID,
+			"imdb_id":         progress.ImdbID,
+			"device":          progress.Device,
+			"last_watched_at": bson.M{"$gte": progress.UpdatedAt.Add(-sessionGap)},
+		}
+		opts := options.FindOne().SetSort(bson.D{{Key: "last_watched_at", Value: -1}})
+
+		var session models.WatchHistoryEntry
+		err := historyCollection.FindOne(ctx, filter, opts).Decode(&session)
+		if err == mongo.ErrNoDocuments {
+			session = models.WatchHistoryEntry{
+				UserID:        progress.UserID,
+				ImdbID:        progress.ImdbID,
+				Device:        progress.Device,
+				StartedAt:     progress.UpdatedAt,
+				LastWatchedAt: progress.UpdatedAt,
+				LastPosition:  progress.PositionSeconds,
+			}
+			if _, err := historyCollection.InsertOne(ctx, session); err != nil {
+				log.Printf("Failed to start history entry for %s: %v", progress.UserID, err)
+			}
+			continue
+		}
+		if err != nil {
+			log.Printf("Failed to load history for %s: %v", progress.UserID, err)
+			continue
+		}
+
+		watched := progress.PositionSeconds - session.LastPosition
+		elapsed := progress.UpdatedAt.Sub(session.LastWatchedAt).Seconds()
+		if watched < 0 {
+			watched = 0
+		}
+		if watched > elapsed {
+			watched = elapsed
+		}
+
+		update := bson.M{
+			"$set": bson.M{
+				"last_watched_at": progress.UpdatedAt,
+				"last_position":   progress.PositionSeconds,
+			},
+			"$inc": bson.M{"watched_seconds": watched},
+		}
+		if _, err := historyCollection.UpdateByID(ctx, session.ID, update); err != nil {
+			log.Printf("Failed to update history entry %s: %v", session.ID.Hex(), err)
+		}
+	}
+}
+
+// GetHistory returns the authenticated user's viewing sessions, newest first,
+// optionally limited to ?from= and ?to=
+func GetHistory() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, limit, err := parsePagination(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		from, _, err := parseDateQuery(c, "from")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		to, toDateOnly, err := parseDateQuery(c, "to")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		filter := bson.M{"user_id": c.GetString("userId")}
+		if from != nil || to != nil {
+			window := bson.M{}
+			if from != nil {
+				window["$gte"] = *from
+			}
+			if to != nil && toDateOnly {
+				// A plain date includes the whole day
+				window["$lt"] = to.Add(24 * time.Hour)
+			} else if to != nil {
+				window["$lte"] = *to
+			}
+			filter["started_at"] = window
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		total, err := historyCollection.CountDocuments(ctx, filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		opts := options.Find().
+			SetSort(bson.D{{Key: "started_at", Value: -1}}).
+			SetSkip((page - 1) * limit).
+			SetLimit(limit)
+		cursor, err := historyCollection.Find(ctx, filter, opts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		defer cursor.Close(ctx)
+
+		history := []models.WatchHistoryEntry{}
+		if err = cursor.All(ctx, &history); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		var imdbIds []string
+		for _, entry := range history {
+			imdbIds = append(imdbIds, entry.ImdbID)
+		}
+		movies, err := findMoviesByImdbIDs(ctx, imdbIds)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		episodes, err := findEpisodesByImdbIDs(ctx, imdbIds)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		for i := range history {
+			if movie, ok := movies[history[i].ImdbID]; ok {
+				history[i].Movie = &movie
+			} else if episode, ok := episodes[history[i].ImdbID]; ok {
+				history[i].Episode = &episode
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"history":     history,
+			"page":        page,
+			"limit":       limit,
+			"total_found": total,
+		})
+	}
+}
+
+// DeleteHistoryEntry removes one of the authenticated user's history entries
+func DeleteHistoryEntry() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := bson.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid history entry ID"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		result, err := historyCollection.DeleteOne(ctx, bson.M{"_id": id, "user_id": c.GetString("userId")})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete history entry"})
+			return
+		}
+		if result.DeletedCount == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "History entry not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "History entry deleted successfully"})
+	}
+}
+
+// ClearHistory removes all of the authenticated user's history entries
+func ClearHistory() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		result, err := historyCollection.DeleteMany(ctx, bson.M{"user_id": c.GetString("userId")})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clear history"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":       "History cleared successfully",
+			"deleted_count": result.DeletedCount,
+		})
+	}
+}
+
+// StartHistoryRetentionJob periodically purges history older than HISTORY_RETENTION_DAYS.
+// A retention of 0 keeps history forever.
+func StartHistoryRetentionJob() {
+	retentionDays := utils.GetEnvInt("HISTORY_RETENTION_DAYS", 365)
+	if retentionDays <= 0 {
+		return
+	}
+	interval := utils.GetEnvDuration("HISTORY_PURGE_INTERVAL", 6*time.Hour)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			purgeHistory(time.Duration(retentionDays) * 24 * time.Hour)
+			<-ticker.C
+		}
+	}()
+}
+
+func purgeHistory(retention time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	cutoff := time.Now().Add(-retention)
+	result, err := historyCollection.DeleteMany(ctx, bson.M{"last_watched_at": bson.M{"$lt": cutoff}})
+	if err != nil {
+		log.Printf("History retention purge failed: %v", err)
+		return
+	}
+	if result.DeletedCount > 0 {
+		log.Printf("History retention purged %d entries older than %s", result.DeletedCount, cutoff.Format(time.RFC3339))
+	}
+}
@@ -0,0 +1,568 @@
+package controllers
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/models"
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// importColumns are the CSV columns understood by the bulk import. List columns (genres,
+// keywords) are separated by "|"; genres may be given by catalog ID, name or alias.
+var importColumns = map[string]bool{
+	"imdb_id": true, "title": true, "poster_path": true, "youtube_id": true, "genres": true,
+	"keywords": true, "admin_review": true, "year": true, "plot": true, "runtime_minutes": true,
+}
+
+// importRow is one parsed row waiting to be written
+type importRow struct {
+	line  int
+	movie models.Movie
+}
+
+// ImportMovies loads movies in bulk from a CSV or NDJSON upload (multipart "file" or the raw body).
+// Every row is validated like POST /movies. mode=insert rejects existing IMDb IDs while
+// mode=upsert updates them; dry_run=true validates and counts without writing.
+func ImportMovies() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		mode := c.DefaultQuery("mode", "insert")
+		if mode != "insert" && mode != "upsert" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "mode must be insert or upsert"})
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, int64(utils.GetEnvInt("IMPORT_MAX_BYTES", 50<<20)))
+		body := io.Reader(c.Request.Body)
+		name := ""
+		if c.ContentType() == "multipart/form-data" {
+			fileHeader, err := c.FormFile("file")
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Import file is too large"})
+				return
+			}
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Import file is required"})
+				return
+			}
+			file, err := fileHeader.Open()
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read import file"})
+				return
+			}
+			defer file.Close()
+			body, name = file, fileHeader.Filename
+		}
+
+		format := importFormat(c.Query("format"), name, c.ContentType())
+		if format == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv or ndjson"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		defer cancel()
+
+		catalog, err := loadGenreCatalog(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to look up genres"})
+			return
+		}
+
+		importer := &movieImporter{
+			catalog:   catalog,
+			changedBy: c.GetString("userId"),
+			mode:      mode,
+			batchSize: max(utils.GetEnvInt("IMPORT_BATCH_SIZE", 500), 1),
+			maxErrors: utils.GetEnvInt("IMPORT_MAX_ERRORS", 1000),
+			seen:      map[string]int{},
+			summary: models.ImportSummary{
+				Format: format,
+				Mode:   mode,
+				DryRun: c.Query("dry_run") == "true",
+				Errors: []models.ImportRowError{},
+			},
+		}
+
+		if format == "csv" {
+			err = importer.readCSV(ctx, body)
+		} else {
+			err = importer.readNDJSON(ctx, body)
+		}
+		if err == nil {
+			err = importer.flush(ctx)
+		}
+
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &tooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Import file is too large", "summary": importer.summary})
+		case errors.Is(err, errBadImportHeader):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case err != nil:
+			// Rows written before the failure stay written; the summary says how far it got
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "summary": importer.summary})
+		default:
+			c.JSON(http.StatusOK, importer.summary)
+		}
+	}
+}
+
+var errBadImportHeader = errors.New("invalid CSV header")
+
+// importFormat picks the format from the query, then the file extension, then the content type
+func importFormat(query, filename, contentType string) string {
+	switch strings.ToLower(query) {
+	case "csv":
+		return "csv"
+	case "ndjson", "jsonl":
+		return "ndjson"
+	case "":
+	default:
+		return ""
+	}
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return "csv"
+	case ".ndjson", ".jsonl":
+		return "ndjson"
+	}
+
+	switch contentType {
+	case "text/csv":
+		return "csv"
+	case "application/x-ndjson", "application/jsonl", "application/json-lines":
+		return "ndjson"
+	}
+	return ""
+}
+
+type movieImporter struct {
+	catalog   genreCatalog
+	changedBy string
+	mode      string
+	batchSize int
+	maxErrors int
+	seen      map[string]int // IMDb ID -> first line it appeared on
+	batch     []importRow
+	summary   models.ImportSummary
+}
+
+func (im *movieImporter) fail(line int, imdbId, message string) {
+	im.summary.Failed++
+	if len(im.summary.Errors) >= im.maxErrors {
+		im.summary.ErrorsTruncated = true
+		return
+	}
+	im.summary.Errors = append(im.summary.Errors, models.ImportRowError{Row: line, ImdbID: imdbId, Error: message})
+}
+
+func (im *movieImporter) readCSV(ctx context.Context, body io.Reader) error {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !importColumns[name] {
+			return fmt.Errorf("%w: unknown column %q", errBadImportHeader, name)
+		}
+		columns[name] = i
+	}
+	if _, ok := columns["imdb_id"]; !ok {
+		return fmt.Errorf("%w: imdb_id column is required", errBadImportHeader)
+	}
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			im.summary.TotalRows++
+			im.fail(line, "", parseErr.Err.Error())
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		im.summary.TotalRows++
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		movie := models.Movie{
+			ImdbID:     field("imdb_id"),
+			Title:      field("title"),
+			PosterPath: field("poster_path"),
+			YouTubeID:  field("youtube_id"),
+			Plot:       field("plot"),
+			Keywords:   splitImportList(field("keywords")),
+		}
+		if review := field("admin_review"); review != "" {
+			movie.AdminReview = &review
+		}
+
+		var genres []models.Genre
+		var unknown []string
+		for _, value := range splitImportList(field("genres")) {
+			definition, ok := im.catalog.lookup(value)
+			if !ok {
+				unknown = append(unknown, value)
+				continue
+			}
+			genres = append(genres, models.Genre{GenreID: definition.GenreID, GenreName: definition.Name})
+		}
+		if len(unknown) > 0 {
+			im.fail(line, movie.ImdbID, "unknown genres: "+strings.Join(unknown, ", "))
+			continue
+		}
+		movie.Genre = genres
+
+		var numberErr error
+		if movie.Year, numberErr = importNumber(field("year")); numberErr != nil {
+			im.fail(line, movie.ImdbID, "year must be a whole number")
+			continue
+		}
+		if movie.RuntimeMinutes, numberErr = importNumber(field("runtime_minutes")); numberErr != nil {
+			im.fail(line, movie.ImdbID, "runtime_minutes must be a whole number")
+			continue
+		}
+
+		if err := im.add(ctx, line, movie); err != nil {
+			return err
+		}
+	}
+}
+
+func (im *movieImporter) readNDJSON(ctx context.Context, body io.Reader) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 4<<20)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		im.summary.TotalRows++
+
+		var movie models.Movie
+		if err := json.Unmarshal([]byte(text), &movie); err != nil {
+			im.fail(line, "", "invalid JSON: "+err.Error())
+			continue
+		}
+
+		// Genres are referenced by catalog ID, as in POST /movies
+		genres := make([]models.Genre, 0, len(movie.Genre))
+		var unknown []string
+		for _, genre := range movie.Genre {
+			definition, ok := im.catalog.byID[genre.GenreID]
+			if !ok {
+				unknown = append(unknown, strconv.Itoa(genre.GenreID))
+				continue
+			}
+			genres = append(genres, models.Genre{GenreID: definition.GenreID, GenreName: definition.Name})
+		}
+		if len(unknown) > 0 {
+			im.fail(line, movie.ImdbID, "unknown genre IDs: "+strings.Join(unknown, ", "))
+			continue
+		}
+		movie.Genre = genres
+
+		if err := im.add(ctx, line, movie); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// add validates a row and queues it, writing a batch once it is full
+func (im *movieImporter) add(ctx context.Context, line int, movie models.Movie) error {
+	// Server-maintained fields are never imported
+	movie.ID = bson.ObjectID{}
+	movie.UserRating = nil
+	movie.PosterVariants = nil
+	movie.MetadataUpdatedAt = nil
+	movie.Status = ""
+	movie.PublishAt = nil
+	movie.PublishedAt = nil
+	movie.DeletedAt = nil
+	movie.DeletedBy = ""
+	movie.Version = 0
+
+	if err := movieValidate.Struct(movie); err != nil {
+		im.fail(line, movie.ImdbID, err.Error())
+		return nil
+	}
+	if first, duplicate := im.seen[movie.ImdbID]; duplicate {
+		im.fail(line, movie.ImdbID, "duplicate of row "+strconv.Itoa(first))
+		return nil
+	}
+	im.seen[movie.ImdbID] = line
+
+	im.batch = append(im.batch, importRow{line: line, movie: movie})
+	if len(im.batch) >= im.batchSize {
+		return im.flush(ctx)
+	}
+	return nil
+}
+
+// flush writes the queued rows with one unordered bulk write: inserts for new movies and updates
+// pinned to the version read here for existing ones. Revisions are recorded from those pre-images.
+func (im *movieImporter) flush(ctx context.Context) error {
+	if len(im.batch) == 0 {
+		return nil
+	}
+	batch := im.batch
+	im.batch = nil
+
+	imdbIds := make([]string, len(batch))
+	for i, row := range batch {
+		imdbIds[i] = row.movie.ImdbID
+	}
+	cursor, err := movieCollection.Find(ctx, bson.M{"imdb_id": bson.M{"$in": imdbIds}})
+	if err != nil {
+		return err
+	}
+	var existingList []models.Movie
+	if err = cursor.All(ctx, &existingList); err != nil {
+		return err
+	}
+	existing := map[string]models.Movie{}
+	for _, movie := range existingList {
+		existing[movie.ImdbID] = movie
+	}
+
+	var writes []mongo.WriteModel
+	var written []importRow     // parallel to writes
+	updates := map[int]bson.M{} // line -> update for existing movies
+	for _, row := range batch {
+		movie := row.movie
+		current, found := existing[movie.ImdbID]
+		switch {
+		case found && current.DeletedAt != nil:
+			im.fail(row.line, movie.ImdbID, "movie is in the trash, restore it first")
+			continue
+		case !found:
+			// Imported movies go through the publication workflow like any other new movie
+			movie.Status = "draft"
+			movie.Version = 1
+			row.movie = movie
+			writes = append(writes, mongo.NewInsertOneModel().SetDocument(movie))
+		case im.mode == "upsert":
+			set := bson.M{
+				"title":       movie.Title,
+				"poster_path": movie.PosterPath,
+				"youtube_id":  movie.YouTubeID,
+				"genre":       movie.Genre,
+			}
+			// Optional fields missing from the row keep their current value
+			optional := map[string]any{
+				"keywords":        movie.Keywords,
+				"admin_review":    movie.AdminReview,
+				"ranking":         movie.Ranking,
+				"year":            movie.Year,
+				"plot":            movie.Plot,
+				"runtime_minutes": movie.RuntimeMinutes,
+			}
+			for field, value := range optional {
+				if !isEmptyImportValue(value) {
+					set[field] = value
+				}
+			}
+			updates[row.line] = bson.M{"$set": set}
+			filter := withVersion(liveMovieFilter(bson.M{"_id": current.ID}), current.Version)
+			writes = append(writes, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(bumpVersion(updates[row.line])))
+		default:
+			im.fail(row.line, movie.ImdbID, "movie already exists")
+			continue
+		}
+		written = append(written, row)
+	}
+
+	// Dry runs stop here: the counts reflect what would have been written
+	if im.summary.DryRun {
+		for _, row := range written {
+			im.count(row, existing)
+		}
+		return nil
+	}
+
+	failedAt := map[int]string{}
+	if len(writes) > 0 {
+		result, err := movieCollection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+		var bulkErr mongo.BulkWriteException
+		if errors.As(err, &bulkErr) && bulkErr.WriteConcernError == nil {
+			for _, writeErr := range bulkErr.WriteErrors {
+				message := writeErr.Message
+				if mongo.IsDuplicateKeyError(writeErr) {
+					// Created by someone else since the existence check
+					message = "movie already exists"
+				}
+				failedAt[written[writeErr.Index].line] = message
+			}
+		} else if err != nil {
+			return err
+		}
+
+		updated := 0
+		for _, row := range written {
+			if _, failed := failedAt[row.line]; !failed && updates[row.line] != nil {
+				updated++
+			}
+		}
+		if result == nil || result.MatchedCount < int64(updated) {
+			if err := im.findSkippedUpdates(ctx, written, existing, updates, failedAt); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, row := range written {
+		if message, failed := failedAt[row.line]; failed {
+			im.fail(row.line, row.movie.ImdbID, message)
+			continue
+		}
+		before, found := existing[row.movie.ImdbID]
+		after := row.movie
+		var beforeRevision *models.Movie
+		if found {
+			after, err = appliedUpdate(before, updates[row.line])
+			if err != nil {
+				return err
+			}
+			beforeRevision = &before
+		}
+		if err := recordMovieRevision(ctx, beforeRevision, &after, im.revision()); err != nil {
+			log.Printf("Failed to record revision of movie %s: %v", after.ImdbID, err)
+		}
+		im.count(row, existing)
+	}
+	return nil
+}
+
+// findSkippedUpdates marks the updates of a bulk write that matched no movie because it was
+// changed, deleted or trashed after flush read it. An update counts as applied when the movie
+// now looks exactly like its pre-image with the update applied.
+func (im *movieImporter) findSkippedUpdates(ctx context.Context, written []importRow, existing map[string]models.Movie, updates map[int]bson.M, failedAt map[int]string) error {
+	var ids []bson.ObjectID
+	for _, row := range written {
+		if updates[row.line] != nil {
+			ids = append(ids, existing[row.movie.ImdbID].ID)
+		}
+	}
+	cursor, err := movieCollection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return err
+	}
+	var currentList []models.Movie
+	if err = cursor.All(ctx, &currentList); err != nil {
+		return err
+	}
+	current := map[string]models.Movie{}
+	for _, movie := range currentList {
+		current[movie.ImdbID] = movie
+	}
+
+	for _, row := range written {
+		if _, failed := failedAt[row.line]; failed || updates[row.line] == nil {
+			continue
+		}
+		expected, err := appliedUpdate(existing[row.movie.ImdbID], updates[row.line])
+		if err != nil {
+			return err
+		}
+		now, found := current[row.movie.ImdbID]
+		if !found || now.DeletedAt != nil {
+			failedAt[row.line] = "movie no longer exists"
+			continue
+		}
+		changes, err := diffMovies(&expected, &now)
+		if err != nil {
+			return err
+		}
+		if now.Version != expected.Version || len(changes) > 0 {
+			failedAt[row.line] = "movie was changed during the import, retry the row"
+		}
+	}
+	return nil
+}
+
+// count adds a written row to the inserted or updated total
+func (im *movieImporter) count(row importRow, existing map[string]models.Movie) {
+	if _, found := existing[row.movie.ImdbID]; found {
+		im.summary.Updated++
+	} else {
+		im.summary.Inserted++
+	}
+}
+
+// revision describes the changes made by this import
+func (im *movieImporter) revision() models.MovieRevision {
+	return models.MovieRevision{ChangedBy: im.changedBy, Source: "import"}
+}
+
+// splitImportList splits a "|"-separated CSV cell, dropping empty entries
+func splitImportList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, "|") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// importNumber parses an optional whole-number CSV cell
+func importNumber(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
+
+// isEmptyImportValue reports whether an optional upsert field was left out of the row
+func isEmptyImportValue(value any) bool {
+	switch v := value.(type) {
+	case []string:
+		return len(v) == 0
+	case *string:
+		return v == nil
+	case *models.Ranking:
+		return v == nil
+	case int:
+		return v == 0
+	case string:
+		return v == ""
+	}
+	return value == nil
+}
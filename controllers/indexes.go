@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// EnsureIndexes creates the unique indexes that handlers rely on to reject concurrent
+// duplicates. Failures are logged rather than fatal so existing duplicate data does not
+// keep the server from starting.
+func EnsureIndexes() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	indexes := []struct {
+		collection *mongo.Collection
+		model      mongo.IndexModel
+	}{
+		// One movie per IMDb ID, so concurrent creates and imports cannot both insert it
+		{movieCollection, mongo.IndexModel{
+			Keys:    bson.D{{Key: "imdb_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		}},
+		// One media asset per title, so packaging claims cannot create a second one
+		{mediaAssetCollection, mongo.IndexModel{
+			Keys:    bson.D{{Key: "imdb_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		}},
+		{seriesCollection, mongo.IndexModel{
+			Keys:    bson.D{{Key: "series_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		}},
+		// An episode's IMDb ID keys its media, so no two episodes may share one
+		{episodeCollection, mongo.IndexModel{
+			Keys:    bson.D{{Key: "imdb_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		}},
+		{episodeCollection, mongo.IndexModel{
+			Keys:    bson.D{{Key: "series_id", Value: 1}, {Key: "season_number", Value: 1}, {Key: "episode_number", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		}},
+		// One review per user and movie, so concurrent first submissions cannot both insert
+		{reviewCollection, mongo.IndexModel{
+			Keys:    bson.D{{Key: "imdb_id", Value: 1}, {Key: "user_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		}},
+		// A person holds each role at most once per movie
+		{creditCollection, mongo.IndexModel{
+			Keys:    bson.D{{Key: "person_id", Value: 1}, {Key: "imdb_id", Value: 1}, {Key: "role", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		}},
+	}
+	for _, index := range indexes {
+		if _, err := index.collection.Indexes().CreateOne(ctx, index.model); err != nil {
+			log.Printf("Failed to create index on %s: %v", index.collection.Name(), err)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Packaged media lives on disk as MEDIA_ROOT/<imdb_id>/master.m3u8 plus its playlists and segments
+var mediaRoot = utils.GetEnvString("MEDIA_ROOT", "media")
+
+// mediaDir returns the directory holding a title's packaged media
+func mediaDir(imdbId string) string {
+	return filepath.Join(mediaRoot, imdbId)
+}
+
+// mediaPath resolves a requested file inside a title's media directory, refusing path traversal
+func mediaPath(imdbId, requested string) (string, bool) {
+	if imdbId == "" || strings.ContainsAny(imdbId, `/\.`) {
+		return "", false
+	}
+	cleaned := filepath.Clean("/" + requested)
+	if cleaned == "/" {
+		return "", false
+	}
+	return filepath.Join(mediaDir(imdbId), cleaned), true
+}
+
+// playableTitleExists reports whether imdbId refers to something that can be streamed: a movie or a series episode.
+// Movies in the trash never count; public also leaves out movies that are not published yet.
+func playableTitleExists(ctx context.Context, imdbId string, public bool) (bool, error) {
+	filter := liveMovieFilter(bson.M{"imdb_id": imdbId})
+	if public {
+		filter = publicMovieFilter(filter)
+	}
+	count, err := movieCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return true, nil
+	}
+
+	count, err = episodeCollection.CountDocuments(ctx, bson.M{"imdb_id": imdbId})
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// CreatePlaybackURL issues a signed, expiring manifest URL for the authenticated user
+func CreatePlaybackURL() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		imdbId := c.Param("imdb_id")
+		if imdbId == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Movie ID required"})
+			return
+		}
+
+		userId := c.GetString("userId")
+		if userId == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		exists, err := playableTitleExists(ctx, imdbId, true)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to look up movie"})
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+			return
+		}
+
+		expiresAt := time.Now().Add(utils.PlaybackURLTTL())
+		query := utils.SignPlayback(userId, imdbId, expiresAt)
+
+		c.JSON(http.StatusOK, gin.H{
+			"imdb_id":      imdbId,
+			"playback_url": utils.PublicBaseURL(c) + "/media/" + imdbId + "/master.m3u8?" + query,
+			"expires_at":   expiresAt.UTC(),
+		})
+	}
+}
+
+// ServeMedia streams packaged media files. Playlists are rewritten so every URI they
+// reference carries the same playback signature the playlist was requested with.
+func ServeMedia() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		imdbId := c.Param("imdb_id")
+		path, ok := mediaPath(imdbId, c.Param("filepath"))
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid media path"})
+			return
+		}
+
+		if strings.HasSuffix(path, ".m3u8") {
+			playlist, err := os.ReadFile(path)
+			if err != nil {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Media not found"})
+				return
+			}
+			if filepath.Base(path) == masterPlaylistName {
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				renditions, err := subtitleRenditions(ctx, imdbId)
+				cancel()
+				if err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load subtitles"})
+					return
+				}
+				playlist = utils.AddSubtitleRenditions(playlist, renditions)
+			}
+			query := utils.PlaybackQuery(c.Request.URL.Query())
+			c.Header("Cache-Control", "no-store")
+			c.Data(http.StatusOK, "application/vnd.apple.mpegurl", utils.SignPlaylist(playlist, query))
+			return
+		}
+
+		if filepath.Base(path) == thumbnailTrackName {
+			track, err := os.ReadFile(path)
+			if err != nil {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Media not found"})
+				return
+			}
+			query := utils.PlaybackQuery(c.Request.URL.Query())
+			c.Header("Cache-Control", "no-store")
+			c.Data(http.StatusOK, "text/vtt; charset=utf-8", utils.SignThumbnailTrack(track, query))
+			return
+		}
+
+		if _, err := os.Stat(path); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Media not found"})
+			return
+		}
+		c.File(path)
+	}
+}
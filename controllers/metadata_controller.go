@@ -0,0 +1,326 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/models"
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var metadataProvider utils.MetadataProvider = utils.NewMetadataProvider()
+
+// PreviewMetadata shows what the metadata provider knows about a title and how its genres
+// map onto the catalog, without changing anything
+func PreviewMetadata() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		imdbId := c.Param("imdb_id")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+		defer cancel()
+
+		metadata, ok := fetchMetadata(ctx, c, imdbId)
+		if !ok {
+			return
+		}
+
+		catalog, err := loadGenreCatalog(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to look up genres"})
+			return
+		}
+		genres, unresolved := matchCatalogGenres(catalog, metadata.Genres)
+
+		count, err := movieCollection.CountDocuments(ctx, bson.M{"imdb_id": imdbId})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"provider":          metadataProvider.Name(),
+			"metadata":          metadata,
+			"genres":            genres,
+			"unresolved_genres": unresolved,
+			"exists":            count > 0,
+		})
+	}
+}
+
+// ImportMetadata creates a movie from provider metadata, or refreshes an existing one.
+// Uploaded posters are never replaced by the provider's poster.
+func ImportMetadata() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		imdbId := c.Param("imdb_id")
+
+		var req models.MetadataImportRequest
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+				return
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+		defer cancel()
+
+		metadata, ok := fetchMetadata(ctx, c, imdbId)
+		if !ok {
+			return
+		}
+
+		catalog, err := loadGenreCatalog(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to look up genres"})
+			return
+		}
+
+		var movie models.Movie
+		status := http.StatusOK
+		err = movieCollection.FindOne(ctx, bson.M{"imdb_id": imdbId}).Decode(&movie)
+		switch {
+		case err == mongo.ErrNoDocuments:
+			now := time.Now()
+			genres, _ := matchCatalogGenres(catalog, metadata.Genres)
+			movie = models.Movie{
+				ImdbID:            imdbId,
+				Title:             metadata.Title,
+				PosterPath:        metadata.PosterURL,
+				YouTubeID:         req.YouTubeID,
+				Genre:             genres,
+				Year:              metadata.Year,
+				Plot:              metadata.Plot,
+				RuntimeMinutes:    metadata.RuntimeMinutes,
+				MetadataUpdatedAt: &now,
+				Status:            "draft",
+				Version:           1,
+			}
+			if err := movieValidate.Struct(movie); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Imported movie is incomplete", "details": err.Error()})
+				return
+			}
+			if _, err := movieCollection.InsertOne(ctx, movie); err != nil {
+				if mongo.IsDuplicateKeyError(err) {
+					c.JSON(http.StatusConflict, gin.H{"error": "Movie was created meanwhile, try again"})
+					return
+				}
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			if err := recordMovieRevision(ctx, nil, &movie, models.MovieRevision{ChangedBy: c.GetString("userId"), Source: "metadata"}); err != nil {
+				log.Printf("Failed to record revision of movie %s: %v", movie.ImdbID, err)
+			}
+			c.Header("ETag", movieETag(movie.Version))
+			status = http.StatusCreated
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		case movie.DeletedAt != nil:
+			c.JSON(http.StatusConflict, gin.H{"error": "Movie is in the trash, restore it first"})
+			return
+		default:
+			// Refreshing an existing movie is an edit like any other, so it must not overwrite
+			// changes the admin has not seen
+			version, ok := ifMatchVersion(c)
+			if !ok {
+				return
+			}
+			revision := models.MovieRevision{ChangedBy: c.GetString("userId"), Source: "metadata"}
+			movie, ok = updateMovieIfMatch(ctx, c, liveMovieFilter(bson.M{"imdb_id": imdbId}), metadataUpdate(catalog, movie, metadata), version, revision)
+			if !ok {
+				return
+			}
+		}
+
+		creditsAdded := 0
+		if req.ImportCredits {
+			creditsAdded, err = importCredits(ctx, imdbId, metadata)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Movie imported but linking credits failed", "details": err.Error()})
+				return
+			}
+		}
+
+		_, unresolved := matchCatalogGenres(catalog, metadata.Genres)
+		c.JSON(status, gin.H{
+			"message":           "Metadata imported",
+			"movie":             movie,
+			"unresolved_genres": unresolved,
+			"credits_added":     creditsAdded,
+		})
+	}
+}
+
+// StartMetadataRefreshJob periodically re-fetches metadata for imported movies whose
+// metadata is older than METADATA_REFRESH_AGE
+func StartMetadataRefreshJob() {
+	interval := utils.GetEnvDuration("METADATA_REFRESH_INTERVAL", 24*time.Hour)
+	if metadataProvider == nil || interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			refreshStaleMetadata()
+			<-ticker.C
+		}
+	}()
+}
+
+func refreshStaleMetadata() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	cutoff := time.Now().Add(-utils.GetEnvDuration("METADATA_REFRESH_AGE", 30*24*time.Hour))
+	opts := options.Find().
+		SetSort(bson.D{{Key: "metadata_updated_at", Value: 1}}).
+		SetLimit(int64(utils.GetEnvInt("METADATA_REFRESH_BATCH", 50)))
+	cursor, err := movieCollection.Find(ctx, liveMovieFilter(bson.M{"metadata_updated_at": bson.M{"$lt": cutoff}}), opts)
+	if err != nil {
+		log.Printf("Metadata refresh failed to list movies: %v", err)
+		return
+	}
+	var movies []models.Movie
+	if err = cursor.All(ctx, &movies); err != nil {
+		log.Printf("Metadata refresh failed to list movies: %v", err)
+		return
+	}
+	if len(movies) == 0 {
+		return
+	}
+
+	catalog, err := loadGenreCatalog(ctx)
+	if err != nil {
+		log.Printf("Metadata refresh failed to load genres: %v", err)
+		return
+	}
+
+	refreshed := 0
+	for _, movie := range movies {
+		metadata, err := metadataProvider.FetchMovie(ctx, movie.ImdbID)
+		if err != nil {
+			log.Printf("Metadata refresh for %s failed: %v", movie.ImdbID, err)
+			continue
+		}
+		revision := models.MovieRevision{ChangedBy: "system", Source: "metadata"}
+		// Pinned to the loaded version so an admin edit made meanwhile is not overwritten
+		filter := withVersion(bson.M{"imdb_id": movie.ImdbID}, movie.Version)
+		if _, err := updateMovie(ctx, filter, metadataUpdate(catalog, movie, metadata), revision); err != nil {
+			log.Printf("Metadata refresh for %s failed to save: %v", movie.ImdbID, err)
+			continue
+		}
+		refreshed++
+	}
+	log.Printf("Metadata refresh updated %d of %d movies", refreshed, len(movies))
+}
+
+// fetchMetadata asks the provider for a title, answering the request itself on failure
+func fetchMetadata(ctx context.Context, c *gin.Context, imdbId string) (utils.MovieMetadata, bool) {
+	if metadataProvider == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Metadata provider not configured"})
+		return utils.MovieMetadata{}, false
+	}
+
+	metadata, err := metadataProvider.FetchMovie(ctx, imdbId)
+	if errors.Is(err, utils.ErrMetadataNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Title not found at metadata provider"})
+		return utils.MovieMetadata{}, false
+	}
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Metadata provider request failed", "details": err.Error()})
+		return utils.MovieMetadata{}, false
+	}
+	return metadata, true
+}
+
+// matchCatalogGenres maps provider genre names onto catalog genres by name or alias
+func matchCatalogGenres(catalog genreCatalog, names []string) (genres []models.Genre, unresolved []string) {
+	genres = []models.Genre{}
+	unresolved = []string{}
+	seen := map[int]bool{}
+	for _, name := range names {
+		definition, ok := catalog.byKey[genreKey(name)]
+		if !ok {
+			unresolved = append(unresolved, name)
+			continue
+		}
+		if !seen[definition.GenreID] {
+			seen[definition.GenreID] = true
+			genres = append(genres, models.Genre{GenreID: definition.GenreID, GenreName: definition.Name})
+		}
+	}
+	return genres, unresolved
+}
+
+// metadataUpdate builds the update of an existing movie with the provider values that are present. Genres
+// are only replaced when at least one maps onto the catalog, and an uploaded poster wins over the provider's.
+func metadataUpdate(catalog genreCatalog, movie models.Movie, metadata utils.MovieMetadata) bson.M {
+	set := bson.M{"metadata_updated_at": time.Now()}
+	if metadata.Title != "" {
+		set["title"] = metadata.Title
+	}
+	if metadata.Year > 0 {
+		set["year"] = metadata.Year
+	}
+	if metadata.Plot != "" {
+		set["plot"] = metadata.Plot
+	}
+	if metadata.RuntimeMinutes > 0 {
+		set["runtime_minutes"] = metadata.RuntimeMinutes
+	}
+	if metadata.PosterURL != "" && len(movie.PosterVariants) == 0 {
+		set["poster_path"] = metadata.PosterURL
+	}
+	if genres, _ := matchCatalogGenres(catalog, metadata.Genres); len(genres) > 0 {
+		set["genre"] = genres
+	}
+
+	return bson.M{"$set": set}
+}
+
+// importCredits links the provider's cast and crew to a movie, matching people by exact name and
+// creating the ones that are missing. Existing credits are left alone; it returns how many were added.
+func importCredits(ctx context.Context, imdbId string, metadata utils.MovieMetadata) (int, error) {
+	roles := []struct {
+		role  string
+		names []string
+	}{
+		{"actor", metadata.Actors},
+		{"director", metadata.Directors},
+		{"writer", metadata.Writers},
+	}
+
+	added := 0
+	for _, role := range roles {
+		for order, name := range role.names {
+			now := time.Now()
+			var person models.Person
+			err := personCollection.FindOneAndUpdate(ctx,
+				bson.M{"name": name},
+				bson.M{"$setOnInsert": bson.M{"name": name, "created_at": now, "updated_at": now}},
+				options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+			).Decode(&person)
+			if err != nil {
+				return added, err
+			}
+
+			filter := bson.M{"person_id": person.ID, "imdb_id": imdbId, "role": role.role}
+			update := bson.M{"$setOnInsert": bson.M{"order": order, "created_at": now}}
+			result, err := creditCollection.UpdateOne(ctx, filter, update, options.UpdateOne().SetUpsert(true))
+			if err != nil {
+				return added, err
+			}
+			added += int(result.UpsertedCount)
+		}
+	}
+	return added, nil
+}
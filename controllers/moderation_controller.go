@@ -0,0 +1,200 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/models"
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ReportReview lets a user flag someone else's review. Once enough users report an approved
+// review it goes back into the moderation queue.
+func ReportReview() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reviewId, err := bson.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid review ID"})
+			return
+		}
+		userId := c.GetString("userId")
+
+		var req models.ReviewReportInput
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+			return
+		}
+		req.Reason = strings.TrimSpace(req.Reason)
+		if err := movieValidate.Struct(req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		var review models.UserReview
+		if err := reviewCollection.FindOne(ctx, bson.M{"_id": reviewId}).Decode(&review); err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Review not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if review.UserID == userId {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot report your own review"})
+			return
+		}
+
+		// One report per user
+		filter := bson.M{"_id": reviewId, "reports.user_id": bson.M{"$ne": userId}}
+		update := bson.M{
+			"$push": bson.M{"reports": models.ReviewReport{UserID: userId, Reason: req.Reason, CreatedAt: time.Now()}},
+			"$inc":  bson.M{"report_count": 1},
+		}
+		var reported models.UserReview
+		opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+		err = reviewCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&reported)
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusOK, gin.H{"message": "Review already reported"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to report review"})
+			return
+		}
+
+		threshold := utils.GetEnvInt("MODERATION_REPORT_THRESHOLD", 3)
+		if reported.ReportCount >= threshold && reported.Status != "pending" && reported.Status != "rejected" {
+			requeue := bson.M{
+				"$set":      bson.M{"status": "pending"},
+				"$addToSet": bson.M{"flags": "reported"},
+			}
+			if _, err := reviewCollection.UpdateByID(ctx, reviewId, requeue); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue review for moderation"})
+				return
+			}
+			if err := recomputeUserRating(ctx, reported.ImdbID); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update movie rating"})
+				return
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Review reported"})
+	}
+}
+
+// GetModerationQueue lists reviews by moderation status (default pending), most reported first
+func GetModerationQueue() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, limit, err := parsePagination(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		status := c.DefaultQuery("status", "pending")
+		if status != "pending" && status != "approved" && status != "rejected" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "status must be pending, approved or rejected"})
+			return
+		}
+		filter := bson.M{"status": status}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		total, err := reviewCollection.CountDocuments(ctx, filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		opts := options.Find().
+			SetSort(bson.D{{Key: "report_count", Value: -1}, {Key: "updated_at", Value: 1}}).
+			SetSkip((page - 1) * limit).
+			SetLimit(limit)
+		cursor, err := reviewCollection.Find(ctx, filter, opts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		defer cursor.Close(ctx)
+
+		reviews := []models.UserReview{}
+		if err = cursor.All(ctx, &reviews); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"reviews":     reviews,
+			"status":      status,
+			"page":        page,
+			"limit":       limit,
+			"total_found": total,
+		})
+	}
+}
+
+// DecideModeration approves or rejects a review, recording the moderator and the time
+func DecideModeration() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reviewId, err := bson.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid review ID"})
+			return
+		}
+
+		var req models.ModerationDecision
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+			return
+		}
+		if err := movieValidate.Struct(req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+			return
+		}
+
+		status := "approved"
+		if req.Decision == "reject" {
+			status = "rejected"
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		now := time.Now()
+		update := bson.M{
+			"$set": bson.M{
+				"status":          status,
+				"moderated_by":    c.GetString("userId"),
+				"moderated_at":    now,
+				"moderation_note": req.Note,
+			},
+		}
+		var review models.UserReview
+		opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+		if err := reviewCollection.FindOneAndUpdate(ctx, bson.M{"_id": reviewId}, update, opts).Decode(&review); err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Review not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record decision"})
+			return
+		}
+
+		if err := recomputeUserRating(ctx, review.ImdbID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Decision recorded but rating update failed"})
+			return
+		}
+
+		c.JSON(http.StatusOK, review)
+	}
+}
@@ -2,11 +2,15 @@ package controllers
 
 import (
 	"context"
+	"log"
 	"net/http"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/database"
 	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/models"
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"go.mongodb.org/mongo-driver/v2/bson"
@@ -51,6 +55,10 @@ import (
 
 var movieCollection *mongo.Collection = database.OpenCollection("Movie")
 var movieValidate = validator.New()
+var reviewAnalyzer utils.ReviewAnalyzer = utils.NewReviewAnalyzer()
+
+// rankingNames are the words a review can be classified into, best first
+var rankingNames = []string{"excellent", "good", "average", "poor", "terrible"}
 
 func GetMovies() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -59,6 +67,15 @@ func GetMovies() gin.HandlerFunc {
 		// This pattern mimics async/await from Node.js
 		// goroutine = async function, channels = await mechanism
 
+		// Same optional filters as the admin export
+		filterCtx, filterCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		filter, status, err := movieListFilter(filterCtx, c)
+		filterCancel()
+		if err != nil {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+
 		// Create channels to receive results
 		moviesChan := make(chan []models.Movie, 1)
 		errorChan := make(chan error, 1)
@@ -86,9 +103,9 @@ func GetMovies() gin.HandlerFunc {
 
 			// FIND OPERATION:
 			// Find returns a CURSOR (pointer to results), not actual data
-			// bson.M{} = empty filter (find all)
-			// Node.js equivalent: Movie.find({}) but returns cursor instead of data
-			cursor, err := movieCollection.Find(ctx, bson.M{})
+			// filter = the optional list filters, bson.M{} (find all) when none are given
+			// Node.js equivalent: Movie.find(filter) but returns cursor instead of data
+			cursor, err := movieCollection.Find(ctx, publicMovieFilter(filter))
 
 			if err != nil {
 				errorChan <- err // Send error to channel
@@ -146,7 +163,7 @@ func GetMovie() gin.HandlerFunc {
 			defer cancel()
 			var movies []models.Movie
 			id := c.Param("imdb_id")
-			cursor, err := movieCollection.Find(ctx, bson.M{"imdb_id": id})
+			cursor, err := movieCollection.Find(ctx, publicMovieFilter(bson.M{"imdb_id": id}))
 			if err != nil {
 				errorChan <- err // Send error to channel
 				return
@@ -164,6 +181,9 @@ func GetMovie() gin.HandlerFunc {
 		select {
 		case movies := <-moviesChan:
 			// Success case - got movies from channel
+			if len(movies) > 0 {
+				c.Header("ETag", movieETag(movies[0].Version))
+			}
 			c.JSON(200, movies)
 		case err := <-errorChan:
 			// Error case - got error from channel
@@ -185,7 +205,7 @@ func GetTopRatedMovies() gin.HandlerFunc {
 			defer cancel()
 
 			// Get top rated movies (rating >= 7, sorted by rating desc)
-			filter := bson.M{"ranking.ranking_value": bson.M{"$gte": 7}}
+			filter := publicMovieFilter(bson.M{"ranking.ranking_value": bson.M{"$gte": 7}})
 			opts := options.Find().SetSort(bson.D{{Key: "ranking.ranking_value", Value: -1}}).SetLimit(20)
 
 			cursor, err := movieCollection.Find(ctx, filter, opts)
@@ -227,6 +247,21 @@ func GetMoviesByGenre() gin.HandlerFunc {
 			return
 		}
 
+		// Resolve the genre ID, name or alias through the catalog; sub-genres are included
+		catalogCtx, catalogCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		catalog, err := loadGenreCatalog(catalogCtx)
+		catalogCancel()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to look up genres"})
+			return
+		}
+		genre, found := catalog.lookup(genreName)
+		if !found {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Genre not found"})
+			return
+		}
+		genreName = genre.Name
+
 		moviesChan := make(chan []models.Movie, 1)
 		errorChan := make(chan error, 1)
 
@@ -235,7 +270,7 @@ func GetMoviesByGenre() gin.HandlerFunc {
 			defer cancel()
 
 			// Find movies with the specified genre
-			filter := bson.M{"genre.genre_name": bson.M{"$regex": genreName, "$options": "i"}}
+			filter := publicMovieFilter(bson.M{"genre.genre_id": bson.M{"$in": catalog.withDescendants(genre.GenreID)}})
 			opts := options.Find().SetLimit(20)
 
 			cursor, err := movieCollection.Find(ctx, filter, opts)
@@ -286,6 +321,24 @@ func MakeMovies() gin.HandlerFunc {
 			return
 		}
 
+		genres, ok := requireCatalogGenres(c, movie.Genre)
+		if !ok {
+			return
+		}
+		movie.Genre = genres
+
+		// Derived from user reviews, never set by the client
+		movie.UserRating = nil
+
+		// New movies are drafts; they only become public through PUT /admin/movies/:imdb_id/status
+		movie.Status = "draft"
+		movie.PublishAt = nil
+		movie.PublishedAt = nil
+		movie.DeletedAt = nil
+		movie.DeletedBy = ""
+		movie.Version = 1
+
+		changedBy := c.GetString("userId")
 		go func() {
 			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
 			defer cancel()
@@ -294,6 +347,9 @@ func MakeMovies() gin.HandlerFunc {
 				errorChan <- err // Send error to channel
 				return
 			}
+			if err := recordMovieRevision(ctx, nil, &movie, models.MovieRevision{ChangedBy: changedBy, Source: "create"}); err != nil {
+				log.Printf("Failed to record revision of movie %s: %v", movie.ImdbID, err)
+			}
 
 			movieMade <- true // Send success signal
 		}()
@@ -305,6 +361,10 @@ func MakeMovies() gin.HandlerFunc {
 			c.JSON(201, gin.H{"message": "Movie created successfully"}) // 201 = Created
 		case err := <-errorChan:
 			// Error case
+			if mongo.IsDuplicateKeyError(err) {
+				c.JSON(http.StatusConflict, gin.H{"error": "Movie already exists"})
+				return
+			}
 			c.JSON(500, gin.H{"error": err.Error()})
 		case <-time.After(10 * time.Second):
 			// Timeout case
@@ -336,16 +396,30 @@ func AdminReviewUpdate() gin.HandlerFunc {
 			return
 		}
 
-		// Simple rating mapping
-		rankingName := getRatingName(req.Rating)
+		version, ok := ifMatchVersion(c)
+		if !ok {
+			return
+		}
+
+		// Let the review analyzer turn the review text into a ranking word.
+		// It falls back to a local, deterministic analysis if the AI provider is unavailable.
+		analysisCtx, cancelAnalysis := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelAnalysis()
+		input := utils.ReviewAnalysis{Review: req.AdminReview, Rating: req.Rating}
+		rankingName, rankingSource, err := utils.AnalyzeReview(analysisCtx, reviewAnalyzer, input, rankingNames)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to analyze review"})
+			return
+		}
 
-		filter := bson.M{"imdb_id": movieId}
+		filter := liveMovieFilter(bson.M{"imdb_id": movieId})
 		update := bson.M{
 			"$set": bson.M{
 				"admin_review": req.AdminReview,
 				"ranking": bson.M{
-					"ranking_value": req.Rating,
-					"ranking_name":  rankingName,
+					"ranking_value":  req.Rating,
+					"ranking_name":   rankingName,
+					"ranking_source": rankingSource,
 				},
 			},
 		}
@@ -353,40 +427,91 @@ func AdminReviewUpdate() gin.HandlerFunc {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
-		result, err := movieCollection.UpdateOne(ctx, filter, update)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update movie"})
+		_, ok = updateMovieIfMatch(ctx, c, filter, update, version, models.MovieRevision{ChangedBy: c.GetString("userId"), Source: "admin_review"})
+		if !ok {
 			return
 		}
 
-		if result.MatchedCount == 0 {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+		c.JSON(http.StatusOK, gin.H{
+			"message":        "Review updated successfully",
+			"admin_review":   req.AdminReview,
+			"rating":         req.Rating,
+			"ranking_name":   rankingName,
+			"ranking_source": rankingSource,
+		})
+	}
+}
+
+func DeleteMovie() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		movieId := c.Param("imdb_id")
+		if movieId == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Movie ID required"})
 			return
 		}
 
-		c.JSON(http.StatusOK, gin.H{
-			"message":      "Review updated successfully",
-			"admin_review": req.AdminReview,
-			"rating":       req.Rating,
-			"ranking_name": rankingName,
-		})
+		version, ok := ifMatchVersion(c)
+		if !ok {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		// Deleted movies go to the trash; watchlists, reviews and credits are kept until the purge
+		update := bson.M{"$set": bson.M{"deleted_at": time.Now(), "deleted_by": c.GetString("userId")}}
+		revision := models.MovieRevision{ChangedBy: c.GetString("userId"), Source: "delete"}
+		if _, ok := updateMovieIfMatch(ctx, c, liveMovieFilter(bson.M{"imdb_id": movieId}), update, version, revision); !ok {
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Movie moved to trash"})
 	}
 }
 
-// Simple rating name mapping - no AI needed!
-func getRatingName(rating int) string {
-	switch {
-	case rating >= 9:
-		return "excellent"
-	case rating >= 7:
-		return "good"
-	case rating >= 5:
-		return "average"
-	case rating >= 3:
-		return "poor"
-	default:
-		return "terrible"
+// removeMovieReferences drops everything keyed by a movie that is about to be purged: per-user data,
+// credits, edit history, recommendation data, subtitles and the packaged media with its keys and files,
+// so a movie created later with the same IMDb ID starts clean. Uploaded poster blobs are kept because
+// their URLs are immutable and may still be cached by clients.
+func removeMovieReferences(ctx context.Context, movieId string) error {
+	// Buffered progress would otherwise be written back by the next flush
+	pendingProgress.Lock()
+	for key, progress := range pendingProgress.entries {
+		if progress.ImdbID == movieId {
+			delete(pendingProgress.entries, key)
+		}
+	}
+	pendingProgress.Unlock()
+
+	collections := []*mongo.Collection{
+		watchlistCollection,
+		creditCollection,
+		movieRevisionCollection,
+		reviewCollection,
+		progressCollection,
+		historyCollection,
+		recommendationFeedbackCollection,
+		itemSimilarityCollection,
+		subtitleCollection,
+		contentKeyCollection,
+		mediaAssetCollection,
+	}
+	for _, collection := range collections {
+		if _, err := collection.DeleteMany(ctx, bson.M{"imdb_id": movieId}); err != nil {
+			return err
+		}
+	}
+
+	// Other movies may still list it as a neighbor until the next similarity build
+	pull := bson.M{"$pull": bson.M{"neighbors": bson.M{"imdb_id": movieId}}}
+	if _, err := itemSimilarityCollection.UpdateMany(ctx, bson.M{"neighbors.imdb_id": movieId}, pull); err != nil {
+		return err
+	}
+
+	if movieId == "" || strings.ContainsAny(movieId, `/\.`) {
+		return nil
 	}
+	return os.RemoveAll(mediaDir(movieId))
 }
 
 // Get user by ID helper function
@@ -404,12 +529,48 @@ func getUserById(userID string) (*models.User, error) {
 	return &user, nil
 }
 
+// findMoviesByImdbIDs loads the public movies with the given IMDb IDs keyed by IMDb ID.
+// Unpublished and deleted movies are left out.
+func findMoviesByImdbIDs(ctx context.Context, imdbIds []string) (map[string]models.Movie, error) {
+	movies := map[string]models.Movie{}
+	if len(imdbIds) == 0 {
+		return movies, nil
+	}
+
+	cursor, err := movieCollection.Find(ctx, publicMovieFilter(bson.M{"imdb_id": bson.M{"$in": imdbIds}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var found []models.Movie
+	if err = cursor.All(ctx, &found); err != nil {
+		return nil, err
+	}
+	for _, movie := range found {
+		movies[movie.ImdbID] = movie
+	}
+	return movies, nil
+}
+
+// RecommendedMoviesMoved answers the retired public GET /movies/recommended/:user_id, which exposed
+// anyone's recommendations, pointing clients at the authenticated route
+func RecommendedMoviesMoved() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusGone, gin.H{
+			"error":    "This endpoint was removed, use GET /movies/recommended with your access token",
+			"moved_to": "/movies/recommended",
+		})
+	}
+}
+
 // Real recommendation system based on user preferences
 func GetRecommendedMovies() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		userID := c.Param("user_id")
+		// Recommendations and their reasons reveal the user's history, so only the user may see them
+		userID := c.GetString("userId")
 		if userID == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "User ID required"})
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 			return
 		}
 
@@ -426,7 +587,32 @@ func GetRecommendedMovies() gin.HandlerFunc {
 			genreNames = append(genreNames, genre.GenreName)
 		}
 
-		if len(genreNames) == 0 {
+		// Dismissed movies are dropped and "more like this" movies seed both paths
+		feedbackCtx, feedbackCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		feedback, err := loadRecommendationFeedback(feedbackCtx, userID)
+		feedbackCancel()
+		if err != nil {
+			log.Printf("Loading recommendation feedback failed for %s: %v", userID, err)
+		}
+
+		// Prefer the collaborative model; new users without signals fall back to genres
+		collabCtx, collabCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		collaborative, err := collaborativeRecommendations(collabCtx, userID, genreNames, feedback, 10)
+		collabCancel()
+		if err != nil {
+			log.Printf("Collaborative recommendations failed for %s: %v", userID, err)
+		}
+		if len(collaborative) > 0 {
+			c.JSON(http.StatusOK, gin.H{
+				"recommended_movies": collaborative,
+				"based_on_genres":    genreNames,
+				"total_found":        len(collaborative),
+				"strategy":           "collaborative",
+			})
+			return
+		}
+
+		if len(genreNames) == 0 && len(feedback.boosted) == 0 {
 			c.JSON(http.StatusOK, gin.H{
 				"message":     "No favorite genres set for user",
 				"movies":      []models.Movie{},
@@ -436,35 +622,83 @@ func GetRecommendedMovies() gin.HandlerFunc {
 		}
 
 		// Find movies in user's favorite genres with good ratings
-		moviesChan := make(chan []models.Movie, 1)
+		moviesChan := make(chan []models.RecommendedMovie, 1)
 		errorChan := make(chan error, 1)
 
 		go func() {
 			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 			defer cancel()
 
-			// Filter movies by user's favorite genres and good ratings
-			filter := bson.M{
-				"genre.genre_name":      bson.M{"$in": genreNames},
-				"ranking.ranking_value": bson.M{"$gte": 6}, // Only recommend good movies
+			recommended := []models.RecommendedMovie{}
+			picked := map[string]bool{}
+			for imdbId := range feedback.suppressed {
+				picked[imdbId] = true
 			}
 
-			// Sort by rating (highest first) and limit results
-			opts := options.Find().SetSort(bson.D{{Key: "ranking.ranking_value", Value: -1}}).SetLimit(10)
-			cursor, err := movieCollection.Find(ctx, filter, opts)
+			// Movies resembling the ones the user asked for more of come first
+			boostedSources, err := findMoviesByImdbIDs(ctx, feedback.boosted)
 			if err != nil {
 				errorChan <- err
 				return
 			}
-			defer cursor.Close(ctx)
+			for _, imdbId := range feedback.boosted {
+				source, ok := boostedSources[imdbId]
+				if !ok {
+					continue
+				}
+				similar, err := findSimilarMovies(ctx, source, 10)
+				if err != nil {
+					errorChan <- err
+					return
+				}
+				for _, scored := range similar {
+					if len(recommended) >= 10 || picked[scored.Movie.ImdbID] {
+						continue
+					}
+					picked[scored.Movie.ImdbID] = true
+					recommended = append(recommended, models.RecommendedMovie{Movie: scored.Movie, Reason: similarReason(source, true)})
+				}
+			}
 
-			var movies []models.Movie
-			if err = cursor.All(ctx, &movies); err != nil {
-				errorChan <- err
-				return
+			if len(genreNames) > 0 && len(recommended) < 10 {
+				excluded := make([]string, 0, len(picked))
+				for imdbId := range picked {
+					excluded = append(excluded, imdbId)
+				}
+
+				// Filter movies by user's favorite genres and good ratings
+				filter := publicMovieFilter(bson.M{
+					"genre.genre_name":      bson.M{"$in": genreNames},
+					"ranking.ranking_value": bson.M{"$gte": 6}, // Only recommend good movies
+					"imdb_id":               bson.M{"$nin": excluded},
+				})
+
+				// Sort by rating (highest first) and limit results
+				opts := options.Find().SetSort(bson.D{{Key: "ranking.ranking_value", Value: -1}}).SetLimit(int64(10 - len(recommended)))
+				cursor, err := movieCollection.Find(ctx, filter, opts)
+				if err != nil {
+					errorChan <- err
+					return
+				}
+				defer cursor.Close(ctx)
+
+				var movies []models.Movie
+				if err = cursor.All(ctx, &movies); err != nil {
+					errorChan <- err
+					return
+				}
+
+				favourite := map[string]bool{}
+				for _, name := range genreNames {
+					favourite[name] = true
+				}
+				for _, movie := range movies {
+					reason, _ := genreReason(movie, favourite)
+					recommended = append(recommended, models.RecommendedMovie{Movie: movie, Reason: reason})
+				}
 			}
 
-			moviesChan <- movies
+			moviesChan <- recommended
 		}()
 
 		select {
@@ -473,6 +707,7 @@ func GetRecommendedMovies() gin.HandlerFunc {
 				"recommended_movies": movies,
 				"based_on_genres":    genreNames,
 				"total_found":        len(movies),
+				"strategy":           "genre",
 			})
 		case err := <-errorChan:
 			c.JSON(500, gin.H{"error": err.Error()})
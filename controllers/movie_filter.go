@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// movieListFilter builds the catalog filter shared by the movie list and the export:
+// ?q= (title contains), ?genre= (catalog ID, name or alias, including sub-genres),
+// ?keyword=, ?year_from= and ?year_to=. No parameters means every movie. On failure it also
+// returns the HTTP status: 400 for invalid parameters, 500 when the genre catalog cannot be loaded.
+func movieListFilter(ctx context.Context, c *gin.Context) (bson.M, int, error) {
+	filter := bson.M{}
+
+	if q := c.Query("q"); q != "" {
+		filter["title"] = bson.M{"$regex": regexp.QuoteMeta(q), "$options": "i"}
+	}
+
+	if genreParam := c.Query("genre"); genreParam != "" {
+		catalog, err := loadGenreCatalog(ctx)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+		genre, ok := catalog.lookup(genreParam)
+		if !ok {
+			return nil, http.StatusBadRequest, errors.New("unknown genre " + strconv.Quote(genreParam))
+		}
+		filter["genre.genre_id"] = bson.M{"$in": catalog.withDescendants(genre.GenreID)}
+	}
+
+	if keyword := c.Query("keyword"); keyword != "" {
+		filter["keywords"] = keyword
+	}
+
+	year := bson.M{}
+	for param, operator := range map[string]string{"year_from": "$gte", "year_to": "$lte"} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, http.StatusBadRequest, errors.New(param + " must be a year")
+		}
+		year[operator] = parsed
+	}
+	if len(year) > 0 {
+		filter["year"] = year
+	}
+
+	return filter, http.StatusOK, nil
+}
@@ -0,0 +1,366 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/database"
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/models"
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var mediaAssetCollection *mongo.Collection = database.OpenCollection("MediaAsset")
+var contentKeyCollection *mongo.Collection = database.OpenCollection("ContentKey")
+
+// Source videos are read from MEDIA_SOURCE_ROOT and transcoded with the external tool at FFMPEG_PATH
+var mediaSourceRoot = utils.GetEnvString("MEDIA_SOURCE_ROOT", "media/sources")
+var ffmpegPath = utils.GetEnvString("FFMPEG_PATH", "ffmpeg")
+
+const mediaPlaylistName = "index.m3u8"
+const masterPlaylistName = "master.m3u8"
+
+// sourcePath resolves a source video relative to MEDIA_SOURCE_ROOT, refusing path traversal
+func sourcePath(requested string) (string, bool) {
+	cleaned := filepath.Clean("/" + requested)
+	if cleaned == "/" {
+		return "", false
+	}
+	return filepath.Join(mediaSourceRoot, cleaned), true
+}
+
+// PackageMovie transcodes a title's source video into HLS in the background,
+// optionally encrypting the segments with AES-128
+func PackageMovie() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		imdbId := c.Param("imdb_id")
+
+		var req models.PackageRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+			return
+		}
+
+		if err := movieValidate.Struct(req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+			return
+		}
+
+		source, ok := sourcePath(req.SourcePath)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid source path"})
+			return
+		}
+		if _, err := os.Stat(source); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Source video not found"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		exists, err := playableTitleExists(ctx, imdbId, false)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to look up movie"})
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+			return
+		}
+
+		asset := models.MediaAsset{
+			ImdbID:              imdbId,
+			SourcePath:          req.SourcePath,
+			Status:              "packaging",
+			Encrypted:           utils.GetEnvBool("HLS_ENCRYPTION", false),
+			KeyRotationSegments: utils.GetEnvInt("HLS_KEY_ROTATION_SEGMENTS", 0),
+			UpdatedAt:           time.Now(),
+		}
+		if req.Encrypt != nil {
+			asset.Encrypted = *req.Encrypt
+		}
+		if req.KeyRotationSegments != nil {
+			asset.KeyRotationSegments = *req.KeyRotationSegments
+		}
+
+		// Only one packaging run per title at a time. The claim succeeds when the title is not
+		// being packaged or its run outlived PACKAGING_TIMEOUT, which means the server died mid-run.
+		// Otherwise the upsert collides with the existing asset on the unique imdb_id index.
+		staleBefore := time.Now().Add(-utils.GetEnvDuration("PACKAGING_TIMEOUT", 2*time.Hour))
+		filter := bson.M{
+			"imdb_id": imdbId,
+			"$or": bson.A{
+				bson.M{"status": bson.M{"$ne": "packaging"}},
+				bson.M{"updated_at": bson.M{"$lt": staleBefore}},
+			},
+		}
+		err = mediaAssetCollection.FindOneAndUpdate(ctx, filter, bson.M{"$set": asset}, options.FindOneAndUpdate().SetUpsert(true)).Err()
+		if err != nil && err != mongo.ErrNoDocuments {
+			if mongo.IsDuplicateKeyError(err) {
+				c.JSON(http.StatusConflict, gin.H{"error": "Movie is already being packaged"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start packaging"})
+			return
+		}
+
+		go func() {
+			status := bson.M{"status": "ready", "updated_at": time.Now()}
+			segments, err := packageHLS(asset, source)
+			if err != nil {
+				log.Printf("Packaging %s failed: %v", imdbId, err)
+				status = bson.M{"status": "failed", "error": err.Error(), "updated_at": time.Now()}
+			} else {
+				status["segment_count"] = segments
+				status["error"] = ""
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if _, err := mediaAssetCollection.UpdateOne(ctx, bson.M{"imdb_id": imdbId}, bson.M{"$set": status}); err != nil {
+				log.Printf("Failed to record packaging status for %s: %v", imdbId, err)
+			}
+
+			// Fresh renditions get fresh scrub previews
+			if status["status"] == "ready" && !enqueueThumbnails(imdbId) {
+				log.Printf("Thumbnail queue full, skipping previews for %s", imdbId)
+			}
+		}()
+
+		c.JSON(http.StatusAccepted, gin.H{
+			"message":               "Packaging started",
+			"imdb_id":               imdbId,
+			"encrypted":             asset.Encrypted,
+			"key_rotation_segments": asset.KeyRotationSegments,
+		})
+	}
+}
+
+// GetMediaAsset returns the packaging status of a title
+func GetMediaAsset() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		var asset models.MediaAsset
+		err := mediaAssetCollection.FindOne(ctx, bson.M{"imdb_id": c.Param("imdb_id")}).Decode(&asset)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Movie has not been packaged"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, asset)
+	}
+}
+
+// packageHLS runs the external transcoder, encrypts the segments when requested and
+// writes the master playlist. It returns the number of segments produced.
+func packageHLS(asset models.MediaAsset, source string) (int, error) {
+	dir := mediaDir(asset.ImdbID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return 0, err
+	}
+
+	// Clear the previous rendition so stale segments are never served with new keys
+	oldSegments, _ := filepath.Glob(filepath.Join(dir, "segment_*.ts"))
+	for _, segment := range oldSegments {
+		os.Remove(segment)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.GetEnvDuration("PACKAGING_TIMEOUT", 2*time.Hour))
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, ffmpegPath,
+		"-y", "-i", source,
+		"-c:v", "libx264", "-c:a", "aac",
+		"-f", "hls",
+		"-hls_time", strconv.Itoa(utils.GetEnvInt("HLS_SEGMENT_SECONDS", 6)),
+		"-hls_playlist_type", "vod",
+		"-hls_segment_filename", filepath.Join(dir, "segment_%05d.ts"),
+		filepath.Join(dir, mediaPlaylistName),
+	)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return 0, fmt.Errorf("transcoder failed: %v: %s", err, lastLines(string(output), 5))
+	}
+
+	playlistPath := filepath.Join(dir, mediaPlaylistName)
+	playlist, err := os.ReadFile(playlistPath)
+	if err != nil {
+		return 0, err
+	}
+	parsed := utils.ParseMediaPlaylist(playlist)
+	if len(parsed.Segments) == 0 {
+		return 0, fmt.Errorf("transcoder produced no segments")
+	}
+
+	if asset.Encrypted {
+		if playlist, err = encryptSegments(asset, dir, playlist, parsed); err != nil {
+			return 0, err
+		}
+		if err := os.WriteFile(playlistPath, playlist, 0o644); err != nil {
+			return 0, err
+		}
+	} else if err := deleteContentKeys(asset.ImdbID); err != nil {
+		return 0, err
+	}
+
+	master := utils.MasterPlaylist(mediaPlaylistName, peakBandwidth(dir, parsed.Segments))
+	if err := os.WriteFile(filepath.Join(dir, masterPlaylistName), master, 0o644); err != nil {
+		return 0, err
+	}
+
+	return len(parsed.Segments), nil
+}
+
+// encryptSegments generates fresh content keys, encrypts every segment in place and
+// returns the media playlist with #EXT-X-KEY tags pointing at the key endpoint
+func encryptSegments(asset models.MediaAsset, dir string, playlist []byte, parsed utils.MediaPlaylist) ([]byte, error) {
+	if err := deleteContentKeys(asset.ImdbID); err != nil {
+		return nil, err
+	}
+
+	keys := map[int][]byte{}
+	var documents []interface{}
+	for i, segment := range parsed.Segments {
+		keyIndex := utils.KeyIndexForSegment(i, asset.KeyRotationSegments)
+		key, ok := keys[keyIndex]
+		if !ok {
+			key = make([]byte, 16)
+			if _, err := rand.Read(key); err != nil {
+				return nil, err
+			}
+			keys[keyIndex] = key
+			documents = append(documents, models.ContentKey{
+				ImdbID:    asset.ImdbID,
+				KeyIndex:  keyIndex,
+				Key:       key,
+				CreatedAt: time.Now(),
+			})
+		}
+
+		path := filepath.Join(dir, filepath.Base(segment.URI))
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		encrypted, err := utils.EncryptSegment(data, key, parsed.MediaSequence+int64(i))
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(path, encrypted, 0o644); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if _, err := contentKeyCollection.InsertMany(ctx, documents); err != nil {
+		return nil, err
+	}
+
+	return utils.InsertKeyTags(playlist, asset.KeyRotationSegments, func(keyIndex int) string {
+		return "/keys/" + asset.ImdbID + "/" + strconv.Itoa(keyIndex)
+	}), nil
+}
+
+func deleteContentKeys(imdbId string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := contentKeyCollection.DeleteMany(ctx, bson.M{"imdb_id": imdbId})
+	return err
+}
+
+// peakBandwidth estimates the BANDWIDTH attribute from the largest segment bitrate
+func peakBandwidth(dir string, segments []utils.Segment) int {
+	peak := 0
+	for _, segment := range segments {
+		info, err := os.Stat(filepath.Join(dir, filepath.Base(segment.URI)))
+		if err != nil || segment.Duration <= 0 {
+			continue
+		}
+		if bitrate := int(float64(info.Size()*8) / segment.Duration); bitrate > peak {
+			peak = bitrate
+		}
+	}
+	if peak == 0 {
+		return 2500000
+	}
+	return peak
+}
+
+func lastLines(output string, n int) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// GetContentKey hands out a content key to an authenticated user entitled to the title
+func GetContentKey() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		imdbId := c.Param("imdb_id")
+		keyIndex, err := strconv.Atoi(c.Param("key_index"))
+		if err != nil || keyIndex < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid key index"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		entitled, err := isEntitled(ctx, c.GetString("userId"), imdbId)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check entitlement"})
+			return
+		}
+		if !entitled {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Not entitled to this title"})
+			return
+		}
+
+		var key models.ContentKey
+		err = contentKeyCollection.FindOne(ctx, bson.M{"imdb_id": imdbId, "key_index": keyIndex}).Decode(&key)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Key not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load key"})
+			return
+		}
+
+		c.Header("Cache-Control", "no-store")
+		c.Data(http.StatusOK, "application/octet-stream", key.Key)
+	}
+}
+
+// isEntitled decides whether a user may decrypt a title: the account must still exist
+// and the title must still be playable
+func isEntitled(ctx context.Context, userId, imdbId string) (bool, error) {
+	if userId == "" {
+		return false, nil
+	}
+	count, err := userCollection.CountDocuments(ctx, bson.M{"user_id": userId})
+	if err != nil || count == 0 {
+		return false, err
+	}
+	return playableTitleExists(ctx, imdbId, false)
+}
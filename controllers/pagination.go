@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parsePagination reads ?page= (1-based) and ?limit= with sane bounds
+func parsePagination(c *gin.Context) (page int64, limit int64, err error) {
+	page, err = strconv.ParseInt(c.DefaultQuery("page", "1"), 10, 64)
+	if err != nil || page < 1 {
+		return 0, 0, errors.New("page must be a positive number")
+	}
+	limit, err = strconv.ParseInt(c.DefaultQuery("limit", "20"), 10, 64)
+	if err != nil || limit < 1 || limit > 100 {
+		return 0, 0, errors.New("limit must be between 1 and 100")
+	}
+	return page, limit, nil
+}
+
+// parseDateQuery reads an optional RFC 3339 timestamp or YYYY-MM-DD date from the query string.
+// dateOnly reports a plain date, which stands for the whole day starting at the returned midnight.
+func parseDateQuery(c *gin.Context, key string) (parsed *time.Time, dateOnly bool, err error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, false, nil
+	}
+	if timestamp, err := time.Parse(time.RFC3339, value); err == nil {
+		return &timestamp, false, nil
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, false, errors.New(key + " must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+	}
+	return &date, true, nil
+}
@@ -0,0 +1,444 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/database"
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/models"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var personCollection *mongo.Collection = database.OpenCollection("Person")
+var creditCollection *mongo.Collection = database.OpenCollection("Credit")
+
+// GetPerson returns a person with their filmography
+func GetPerson() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		personId, err := bson.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid person ID"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		var person models.Person
+		if err := personCollection.FindOne(ctx, bson.M{"_id": personId}).Decode(&person); err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Person not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		cursor, err := creditCollection.Find(ctx, bson.M{"person_id": personId})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		var credits []models.Credit
+		if err = cursor.All(ctx, &credits); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		imdbIds := make([]string, len(credits))
+		for i, credit := range credits {
+			imdbIds[i] = credit.ImdbID
+		}
+		movies, err := findMoviesByImdbIDs(ctx, imdbIds)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		filmography := []models.FilmographyEntry{}
+		for _, credit := range credits {
+			movie, ok := movies[credit.ImdbID]
+			if !ok {
+				continue
+			}
+			filmography = append(filmography, models.FilmographyEntry{
+				Movie: models.MovieSummary{
+					ImdbID:     movie.ImdbID,
+					Title:      movie.Title,
+					PosterPath: movie.PosterPath,
+					Genre:      movie.Genre,
+					Ranking:    movie.Ranking,
+				},
+				Role:      credit.Role,
+				Character: credit.Character,
+			})
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"person":      person,
+			"filmography": filmography,
+		})
+	}
+}
+
+// GetMovieCredits returns a movie's cast in billing order and its directors and writers
+func GetMovieCredits() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		imdbId := c.Param("imdb_id")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		count, err := movieCollection.CountDocuments(ctx, publicMovieFilter(bson.M{"imdb_id": imdbId}))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if count == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+			return
+		}
+
+		pipeline := mongo.Pipeline{
+			{{Key: "$match", Value: bson.M{"imdb_id": imdbId}}},
+			{{Key: "$lookup", Value: bson.M{
+				"from":         "Person",
+				"localField":   "person_id",
+				"foreignField": "_id",
+				"as":           "person",
+			}}},
+			{{Key: "$unwind", Value: "$person"}},
+			{{Key: "$sort", Value: bson.D{{Key: "order", Value: 1}, {Key: "person.name", Value: 1}}}},
+		}
+		cursor, err := creditCollection.Aggregate(ctx, pipeline)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		var credits []models.Credit
+		if err = cursor.All(ctx, &credits); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		cast, directors, writers := []models.Credit{}, []models.Credit{}, []models.Credit{}
+		for _, credit := range credits {
+			switch credit.Role {
+			case "actor":
+				cast = append(cast, credit)
+			case "director":
+				directors = append(directors, credit)
+			case "writer":
+				writers = append(writers, credit)
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"imdb_id":   imdbId,
+			"cast":      cast,
+			"directors": directors,
+			"writers":   writers,
+		})
+	}
+}
+
+// CreatePerson adds a person to the directory
+func CreatePerson() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var person models.Person
+		if err := c.ShouldBindJSON(&person); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
+			return
+		}
+		if err := movieValidate.Struct(person); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		person.ID = bson.NewObjectID()
+		person.CreatedAt = time.Now()
+		person.UpdatedAt = person.CreatedAt
+		if _, err := personCollection.InsertOne(ctx, person); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusCreated, person)
+	}
+}
+
+// UpdatePerson replaces a person's details
+func UpdatePerson() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		personId, err := bson.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid person ID"})
+			return
+		}
+
+		var person models.Person
+		if err := c.ShouldBindJSON(&person); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
+			return
+		}
+		if err := movieValidate.Struct(person); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		update := bson.M{"$set": bson.M{
+			"name":         person.Name,
+			"bio":          person.Bio,
+			"photo_url":    person.PhotoURL,
+			"external_ids": person.ExternalIDs,
+			"updated_at":   time.Now(),
+		}}
+		opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+		var updated models.Person
+		if err := personCollection.FindOneAndUpdate(ctx, bson.M{"_id": personId}, update, opts).Decode(&updated); err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Person not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, updated)
+	}
+}
+
+// DeletePerson removes a person and all of their credits
+func DeletePerson() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		personId, err := bson.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid person ID"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		result, err := personCollection.DeleteOne(ctx, bson.M{"_id": personId})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if result.DeletedCount == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Person not found"})
+			return
+		}
+
+		if _, err := creditCollection.DeleteMany(ctx, bson.M{"person_id": personId}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Person deleted but removing their credits failed"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Person deleted"})
+	}
+}
+
+// AddCredit links a person to a movie
+func AddCredit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		imdbId := c.Param("imdb_id")
+
+		var req models.CreditInput
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+			return
+		}
+		if err := movieValidate.Struct(req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		credit, status, err := buildCredit(ctx, req)
+		if err != nil {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+
+		count, err := movieCollection.CountDocuments(ctx, bson.M{"imdb_id": imdbId})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if count == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+			return
+		}
+
+		// A person holds each role at most once per movie, enforced by a unique index
+		credit.ID = bson.NewObjectID()
+		credit.ImdbID = imdbId
+		credit.CreatedAt = time.Now()
+		if _, err := creditCollection.InsertOne(ctx, credit); err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				c.JSON(http.StatusConflict, gin.H{"error": "Credit already exists"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusCreated, credit)
+	}
+}
+
+// UpdateCredit changes the person, role, character or billing order of a credit
+func UpdateCredit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		creditId, err := bson.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid credit ID"})
+			return
+		}
+
+		var req models.CreditInput
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+			return
+		}
+		if err := movieValidate.Struct(req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		credit, status, err := buildCredit(ctx, req)
+		if err != nil {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+
+		update := bson.M{"$set": bson.M{
+			"person_id": credit.PersonID,
+			"role":      credit.Role,
+			"character": credit.Character,
+			"order":     credit.Order,
+		}}
+		opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+		var updated models.Credit
+		if err := creditCollection.FindOneAndUpdate(ctx, bson.M{"_id": creditId}, update, opts).Decode(&updated); err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Credit not found"})
+				return
+			}
+			if mongo.IsDuplicateKeyError(err) {
+				c.JSON(http.StatusConflict, gin.H{"error": "Credit already exists"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, updated)
+	}
+}
+
+// DeleteCredit removes a credit link
+func DeleteCredit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		creditId, err := bson.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid credit ID"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		result, err := creditCollection.DeleteOne(ctx, bson.M{"_id": creditId})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if result.DeletedCount == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Credit not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Credit deleted"})
+	}
+}
+
+// buildCredit checks that the credited person exists and drops the character name for crew roles.
+// On failure it also returns the HTTP status to respond with.
+func buildCredit(ctx context.Context, req models.CreditInput) (models.Credit, int, error) {
+	personId, err := bson.ObjectIDFromHex(req.PersonID)
+	if err != nil {
+		return models.Credit{}, http.StatusBadRequest, errors.New("Invalid person ID")
+	}
+
+	count, err := personCollection.CountDocuments(ctx, bson.M{"_id": personId})
+	if err != nil {
+		return models.Credit{}, http.StatusInternalServerError, err
+	}
+	if count == 0 {
+		return models.Credit{}, http.StatusNotFound, errors.New("Person not found")
+	}
+
+	credit := models.Credit{PersonID: personId, Role: req.Role, Order: req.Order}
+	if req.Role == "actor" {
+		credit.Character = req.Character
+	}
+	return credit, http.StatusOK, nil
+}
+
+// movieCast returns the person IDs of the actors credited on each of the given movies
+func movieCast(ctx context.Context, imdbIds []string) (map[string][]string, error) {
+	cast := map[string][]string{}
+	if len(imdbIds) == 0 {
+		return cast, nil
+	}
+
+	filter := bson.M{"imdb_id": bson.M{"$in": imdbIds}, "role": "actor"}
+	opts := options.Find().SetProjection(bson.M{"imdb_id": 1, "person_id": 1})
+	cursor, err := creditCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var credits []models.Credit
+	if err = cursor.All(ctx, &credits); err != nil {
+		return nil, err
+	}
+	for _, credit := range credits {
+		cast[credit.ImdbID] = append(cast[credit.ImdbID], credit.PersonID.Hex())
+	}
+	return cast, nil
+}
+
+// moviesWithCast returns the IMDb IDs of movies any of the given people acted in
+func moviesWithCast(ctx context.Context, personIds []string) ([]string, error) {
+	ids := make([]bson.ObjectID, 0, len(personIds))
+	for _, personId := range personIds {
+		if id, err := bson.ObjectIDFromHex(personId); err == nil {
+			ids = append(ids, id)
+		}
+	}
+
+	var imdbIds []string
+	err := creditCollection.Distinct(ctx, "imdb_id", bson.M{"person_id": bson.M{"$in": ids}, "role": "actor"}).Decode(&imdbIds)
+	if err != nil {
+		return nil, err
+	}
+	return imdbIds, nil
+}
@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/models"
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var blobStore utils.BlobStore = utils.NewBlobStore()
+
+// The canonical poster is the largest variant; the others are listed in poster_variants
+const canonicalPosterVariant = "hero"
+
+// posterKey builds the blob key of a poster variant. The content hash makes every
+// upload a new URL, which is what allows the variants to be cached forever.
+func posterKey(imdbId, hash, variant string) string {
+	return "posters/" + imdbId + "/" + hash + "/" + variant + ".jpg"
+}
+
+// UploadPoster stores an uploaded poster, generates resized JPEG variants and points
+// the movie's poster_path at our own canonical URL
+func UploadPoster() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		imdbId := c.Param("imdb_id")
+		version, ok := ifMatchVersion(c)
+		if !ok {
+			return
+		}
+
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Poster file is required"})
+			return
+		}
+		if fileHeader.Size > int64(utils.GetEnvInt("POSTER_MAX_BYTES", 10<<20)) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Poster file is too large"})
+			return
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read poster file"})
+			return
+		}
+		defer file.Close()
+		data, err := io.ReadAll(file)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read poster file"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		exists, err := playableTitleExists(ctx, imdbId, false)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to look up movie"})
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+			return
+		}
+
+		variants, err := storePosterVariants(ctx, imdbId, data, utils.PublicBaseURL(c))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to process poster", "details": err.Error()})
+			return
+		}
+
+		update := bson.M{
+			"$set": bson.M{
+				"poster_path":     variants[canonicalPosterVariant],
+				"poster_variants": variants,
+			},
+		}
+		revision := models.MovieRevision{ChangedBy: c.GetString("userId"), Source: "poster"}
+		if _, ok := updateMovieIfMatch(ctx, c, liveMovieFilter(bson.M{"imdb_id": imdbId}), update, version, revision); !ok {
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"message":         "Poster uploaded successfully",
+			"poster_path":     variants[canonicalPosterVariant],
+			"poster_variants": variants,
+		})
+	}
+}
+
+// storePosterVariants decodes an image, writes every resized variant to the blob store and
+// returns their public URLs keyed by variant name
+func storePosterVariants(ctx context.Context, imdbId string, data []byte, baseURL string) (map[string]string, error) {
+	img, _, err := utils.DecodeImage(data)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:8])
+	quality := utils.GetEnvInt("POSTER_JPEG_QUALITY", 82)
+
+	variants := map[string]string{}
+	for _, variant := range utils.PosterVariants {
+		encoded, err := utils.EncodeJPEG(utils.ResizeToWidth(img, variant.Width), quality)
+		if err != nil {
+			return nil, err
+		}
+		key := posterKey(imdbId, hash, variant.Name)
+		if err := blobStore.Put(ctx, key, encoded); err != nil {
+			return nil, err
+		}
+		variants[variant.Name] = baseURL + "/" + key
+	}
+	return variants, nil
+}
+
+// GetPoster serves a poster variant with long-lived cache headers
+func GetPoster() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		imdbId := c.Param("imdb_id")
+		hash := c.Param("hash")
+		variant := strings.TrimSuffix(c.Param("variant"), ".jpg")
+		if strings.ContainsAny(imdbId+hash+variant, `/\.`) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid poster path"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		blob, err := blobStore.Open(ctx, posterKey(imdbId, hash, variant))
+		if err != nil {
+			if errors.Is(err, utils.ErrBlobNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Poster not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load poster"})
+			return
+		}
+		defer blob.Close()
+
+		// Poster URLs embed a content hash, so a given URL never changes
+		c.Header("Cache-Control", "public, max-age=31536000, immutable")
+		c.Header("Content-Type", "image/jpeg")
+		http.ServeContent(c.Writer, c.Request, variant+".jpg", time.Time{}, blob)
+	}
+}
@@ -0,0 +1,308 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/database"
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/models"
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var progressCollection *mongo.Collection = database.OpenCollection("WatchProgress")
+
+// Players report progress every few seconds. Updates are coalesced in memory per user and title
+// and only the latest one is written when the buffer is flushed.
+var pendingProgress = struct {
+	sync.Mutex
+	entries map[string]models.WatchProgress
+}{entries: map[string]models.WatchProgress{}}
+
+func progressKey(userId, imdbId string) string {
+	return userId + "|" + imdbId
+}
+
+// finishedThreshold is the fraction of a title after which it counts as watched
+func finishedThreshold() float64 {
+	return utils.GetEnvFloat("PROGRESS_FINISHED_THRESHOLD", 0.9)
+}
+
+// UpdateProgress records the playback position of the authenticated user
+func UpdateProgress() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		imdbId := c.Param("imdb_id")
+		if imdbId == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Movie ID required"})
+			return
+		}
+
+		var req models.ProgressUpdate
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+			return
+		}
+		if err := movieValidate.Struct(req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+			return
+		}
+
+		// Only titles the user can play get progress, so buffered entries never refer to
+		// unpublished, trashed or unknown movies. Heartbeats for a title already buffered
+		// were checked when it entered the buffer, which keeps them off the database.
+		key := progressKey(c.GetString("userId"), imdbId)
+		pendingProgress.Lock()
+		_, buffered := pendingProgress.entries[key]
+		pendingProgress.Unlock()
+		if !buffered {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			exists, err := playableTitleExists(ctx, imdbId, true)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to look up movie"})
+				return
+			}
+			if !exists {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+				return
+			}
+		}
+
+		progress := models.WatchProgress{
+			UserID:          c.GetString("userId"),
+			ImdbID:          imdbId,
+			PositionSeconds: math.Min(*req.PositionSeconds, req.DurationSeconds),
+			DurationSeconds: req.DurationSeconds,
+			Device:          req.Device,
+			UpdatedAt:       time.Now(),
+		}
+		progress.Finished = progress.PositionSeconds/progress.DurationSeconds >= finishedThreshold()
+
+		pendingProgress.Lock()
+		pendingProgress.entries[key] = progress
+		pendingProgress.Unlock()
+
+		c.JSON(http.StatusAccepted, progress)
+	}
+}
+
+// GetProgress returns the resume position of the authenticated user for one title
+func GetProgress() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId := c.GetString("userId")
+		imdbId := c.Param("imdb_id")
+
+		pendingProgress.Lock()
+		progress, ok := pendingProgress.entries[progressKey(userId, imdbId)]
+		pendingProgress.Unlock()
+		if ok {
+			c.JSON(http.StatusOK, progress)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		err := progressCollection.FindOne(ctx, bson.M{"user_id": userId, "imdb_id": imdbId}).Decode(&progress)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusNotFound, gin.H{"error": "No progress recorded"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, progress)
+	}
+}
+
+// GetContinueWatching lists the user's partially watched titles, most recently watched first
+func GetContinueWatching() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId := c.GetString("userId")
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+		if err != nil || limit < 1 || limit > 100 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 100"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		filter := bson.M{"user_id": userId, "finished": false, "position_seconds": bson.M{"$gt": 0}}
+		opts := options.Find().SetSort(bson.D{{Key: "updated_at", Value: -1}}).SetLimit(int64(limit))
+		cursor, err := progressCollection.Find(ctx, filter, opts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		defer cursor.Close(ctx)
+
+		var stored []models.WatchProgress
+		if err = cursor.All(ctx, &stored); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		// Unflushed updates are newer than anything in the database
+		latest := map[string]models.WatchProgress{}
+		for _, progress := range stored {
+			latest[progress.ImdbID] = progress
+		}
+		pendingProgress.Lock()
+		for _, progress := range pendingProgress.entries {
+			if progress.UserID == userId {
+				latest[progress.ImdbID] = progress
+			}
+		}
+		pendingProgress.Unlock()
+
+		var inProgress []models.WatchProgress
+		var imdbIds []string
+		for _, progress := range latest {
+			if progress.Finished || progress.PositionSeconds <= 0 {
+				continue
+			}
+			inProgress = append(inProgress, progress)
+			imdbIds = append(imdbIds, progress.ImdbID)
+		}
+		sort.Slice(inProgress, func(i, j int) bool {
+			return inProgress[i].UpdatedAt.After(inProgress[j].UpdatedAt)
+		})
+
+		movies, err := findMoviesByImdbIDs(ctx, imdbIds)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		episodes, err := findEpisodesByImdbIDs(ctx, imdbIds)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		items := []models.ContinueWatchingItem{}
+		for _, progress := range inProgress {
+			item := models.ContinueWatchingItem{
+				PositionSeconds: progress.PositionSeconds,
+				DurationSeconds: progress.DurationSeconds,
+				ProgressPercent: math.Round(progress.PositionSeconds/progress.DurationSeconds*1000) / 10,
+				Device:          progress.Device,
+				UpdatedAt:       progress.UpdatedAt,
+			}
+			if movie, ok := movies[progress.ImdbID]; ok {
+				item.Movie = &movie
+			} else if episode, ok := episodes[progress.ImdbID]; ok {
+				item.Episode = &episode
+			} else {
+				continue
+			}
+			items = append(items, item)
+			if len(items) == limit {
+				break
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"continue_watching": items,
+			"total_found":       len(items),
+		})
+	}
+}
+
+// StartProgressFlusher periodically writes coalesced progress updates in one bulk operation
+func StartProgressFlusher() {
+	interval := utils.GetEnvDuration("PROGRESS_FLUSH_INTERVAL", 10*time.Second)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			flushProgress()
+		}
+	}()
+}
+
+// FlushProgress writes buffered progress updates right away. main calls it on shutdown
+// so updates reported since the last tick are not lost.
+func FlushProgress() {
+	flushProgress()
+}
+
+func flushProgress() {
+	pendingProgress.Lock()
+	batch := pendingProgress.entries
+	pendingProgress.entries = map[string]models.WatchProgress{}
+	pendingProgress.Unlock()
+
+	if len(batch) == 0 {
+		return
+	}
+
+	writes := make([]mongo.WriteModel, 0, len(batch))
+	for _, progress := range batch {
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"user_id": progress.UserID, "imdb_id": progress.ImdbID}).
+			SetUpdate(bson.M{"$set": bson.M{
+				"position_seconds": progress.PositionSeconds,
+				"duration_seconds": progress.DurationSeconds,
+				"device":           progress.Device,
+				"finished":         progress.Finished,
+				"updated_at":       progress.UpdatedAt,
+			}}).
+			SetUpsert(true))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if _, err := progressCollection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+		log.Printf("Failed to flush %d progress updates: %v", len(writes), err)
+		requeueProgress(batch)
+		return
+	}
+
+	recordHistory(batch)
+}
+
+// requeueProgress puts a failed batch back unless a newer update arrived in the meantime
+func requeueProgress(batch map[string]models.WatchProgress) {
+	pendingProgress.Lock()
+	defer pendingProgress.Unlock()
+	for key, progress := range batch {
+		if _, newer := pendingProgress.entries[key]; !newer {
+			pendingProgress.entries[key] = progress
+		}
+	}
+}
+
+// latestProgress returns the user's most recently updated progress among imdbIds, including
+// updates still waiting to be flushed. ok is false when none of the titles were played.
+func latestProgress(ctx context.Context, userId string, imdbIds []string) (latest models.WatchProgress, ok bool, err error) {
+	opts := options.FindOne().SetSort(bson.D{{Key: "updated_at", Value: -1}})
+	err = progressCollection.FindOne(ctx, bson.M{"user_id": userId, "imdb_id": bson.M{"$in": imdbIds}}, opts).Decode(&latest)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return latest, false, err
+	}
+	ok = err == nil
+
+	pendingProgress.Lock()
+	defer pendingProgress.Unlock()
+	for _, imdbId := range imdbIds {
+		pending, found := pendingProgress.entries[progressKey(userId, imdbId)]
+		if found && (!ok || pending.UpdatedAt.After(latest.UpdatedAt)) {
+			latest, ok = pending, true
+		}
+	}
+	return latest, ok, nil
+}
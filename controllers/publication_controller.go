@@ -0,0 +1,246 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/models"
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// publicationTransitions lists the statuses a movie may move to from each status.
+// Movies without a status are treated as published.
+var publicationTransitions = map[string][]string{
+	"draft":       {"in_review", "published"},
+	"in_review":   {"draft", "published"},
+	"published":   {"unpublished"},
+	"unpublished": {"draft", "published"},
+}
+
+// liveMovieFilter restricts a movie filter to movies that are not in the trash
+func liveMovieFilter(filter bson.M) bson.M {
+	live := bson.M{"deleted_at": bson.M{"$exists": false}}
+	for key, value := range filter {
+		live[key] = value
+	}
+	return live
+}
+
+// publicMovieFilter restricts a movie filter to what anonymous visitors may see
+func publicMovieFilter(filter bson.M) bson.M {
+	public := liveMovieFilter(filter)
+	public["status"] = bson.M{"$in": bson.A{nil, "published"}}
+	return public
+}
+
+// UpdatePublication changes a movie's publication status and schedule
+func UpdatePublication() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		imdbId := c.Param("imdb_id")
+
+		var req models.PublicationUpdate
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+			return
+		}
+		if err := movieValidate.Struct(req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+			return
+		}
+		if req.PublishAt != nil && req.Status != "draft" && req.Status != "in_review" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Only draft or in_review movies can be scheduled"})
+			return
+		}
+		version, ok := ifMatchVersion(c)
+		if !ok {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		var movie models.Movie
+		if err := movieCollection.FindOne(ctx, liveMovieFilter(bson.M{"imdb_id": imdbId})).Decode(&movie); err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if !matchLoadedVersion(c, movie, version) {
+			return
+		}
+
+		current := movie.Status
+		if current == "" {
+			current = "published"
+		}
+		if req.Status != current {
+			allowed := false
+			for _, next := range publicationTransitions[current] {
+				allowed = allowed || next == req.Status
+			}
+			if !allowed {
+				c.JSON(http.StatusConflict, gin.H{"error": "Cannot move a " + current + " movie to " + req.Status})
+				return
+			}
+		}
+
+		set := bson.M{"status": req.Status}
+		update := bson.M{"$set": set}
+		if req.PublishAt != nil {
+			set["publish_at"] = req.PublishAt
+		} else {
+			update["$unset"] = bson.M{"publish_at": ""}
+		}
+		if req.Status == "published" && current != "published" {
+			set["published_at"] = time.Now()
+		}
+
+		// Pinning the version that was checked keeps a concurrent change from being overwritten
+		filter := liveMovieFilter(bson.M{"imdb_id": imdbId})
+		updated, ok := updateMovieIfMatch(ctx, c, filter, update, movie.Version, models.MovieRevision{ChangedBy: c.GetString("userId"), Source: "publication"})
+		if !ok {
+			return
+		}
+
+		c.JSON(http.StatusOK, updated)
+	}
+}
+
+// GetAdminMovie returns one movie in any publication state with its ETag, so admins can edit drafts
+func GetAdminMovie() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		imdbId := c.Param("imdb_id")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		var movie models.Movie
+		if err := movieCollection.FindOne(ctx, liveMovieFilter(bson.M{"imdb_id": imdbId})).Decode(&movie); err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Header("ETag", movieETag(movie.Version))
+		c.JSON(http.StatusOK, movie)
+	}
+}
+
+// GetAdminMovies lists movies in any publication state, filtered like GET /movies plus ?status=
+func GetAdminMovies() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, limit, err := parsePagination(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		filter, status, err := movieListFilter(ctx, c)
+		if err != nil {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		filter = liveMovieFilter(filter)
+		switch status := c.Query("status"); status {
+		case "":
+		case "published":
+			filter["status"] = bson.M{"$in": bson.A{nil, "published"}}
+		case "draft", "in_review", "unpublished":
+			filter["status"] = status
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "status must be draft, in_review, published or unpublished"})
+			return
+		}
+
+		total, err := movieCollection.CountDocuments(ctx, filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		opts := options.Find().
+			SetSort(bson.D{{Key: "title", Value: 1}}).
+			SetSkip((page - 1) * limit).
+			SetLimit(limit)
+		cursor, err := movieCollection.Find(ctx, filter, opts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		movies := []models.Movie{}
+		if err = cursor.All(ctx, &movies); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"movies":      movies,
+			"page":        page,
+			"limit":       limit,
+			"total_found": total,
+		})
+	}
+}
+
+// StartPublishScheduler publishes draft and in-review movies once their publish_at time has passed
+func StartPublishScheduler() {
+	interval := utils.GetEnvDuration("PUBLISH_SCHEDULER_INTERVAL", time.Minute)
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			publishScheduledMovies()
+			<-ticker.C
+		}
+	}()
+}
+
+func publishScheduledMovies() {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	// Movies are published one at a time so each gets its own revision
+	published := 0
+	for {
+		now := time.Now()
+		filter := liveMovieFilter(bson.M{
+			"status":     bson.M{"$in": bson.A{"draft", "in_review"}},
+			"publish_at": bson.M{"$lte": now},
+		})
+		update := bson.M{
+			"$set":   bson.M{"status": "published", "published_at": now},
+			"$unset": bson.M{"publish_at": ""},
+		}
+		_, err := updateMovie(ctx, filter, update, models.MovieRevision{ChangedBy: "system", Source: "scheduler"})
+		if err == mongo.ErrNoDocuments {
+			break
+		}
+		if err != nil {
+			log.Printf("Scheduled publishing failed: %v", err)
+			break
+		}
+		published++
+	}
+	if published > 0 {
+		log.Printf("Published %d scheduled movies", published)
+	}
+}
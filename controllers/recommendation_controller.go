@@ -0,0 +1,161 @@
+package controllers
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/models"
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// movieFeatures extracts the similarity signals of a movie. The admin ranking is preferred
+// over the community average as the rating signal.
+func movieFeatures(movie models.Movie) utils.SimilarityFeatures {
+	features := utils.SimilarityFeatures{Keywords: movie.Keywords}
+	for _, genre := range movie.Genre {
+		features.GenreIDs = append(features.GenreIDs, genre.GenreID)
+	}
+
+	if movie.Ranking != nil && movie.Ranking.RankingValue > 0 {
+		rating := float64(movie.Ranking.RankingValue)
+		features.Rating = &rating
+	} else if movie.UserRating != nil && movie.UserRating.Count > 0 {
+		rating := movie.UserRating.Average
+		features.Rating = &rating
+	}
+	return features
+}
+
+// GetSimilarMovies returns "more like this" movies for a movie, scored by weighted genre overlap,
+// rating proximity, shared keywords and shared cast
+func GetSimilarMovies() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		imdbId := c.Param("imdb_id")
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+		if err != nil || limit < 1 || limit > 50 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 50"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		var source models.Movie
+		if err := movieCollection.FindOne(ctx, publicMovieFilter(bson.M{"imdb_id": imdbId})).Decode(&source); err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		similar, err := findSimilarMovies(ctx, source, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"source_imdb_id": imdbId,
+			"similar_movies": similar,
+			"total_found":    len(similar),
+		})
+	}
+}
+
+// findSimilarMovies scores movies sharing a genre, keyword or actor with source and returns the best ones
+func findSimilarMovies(ctx context.Context, source models.Movie, limit int) ([]models.ScoredMovie, error) {
+	sourceFeatures := movieFeatures(source)
+	sourceCast, err := movieCast(ctx, []string{source.ImdbID})
+	if err != nil {
+		return nil, err
+	}
+	sourceFeatures.Cast = sourceCast[source.ImdbID]
+
+	// Only movies sharing at least one genre, keyword or actor can score meaningfully
+	var overlap bson.A
+	if len(sourceFeatures.GenreIDs) > 0 {
+		overlap = append(overlap, bson.M{"genre.genre_id": bson.M{"$in": sourceFeatures.GenreIDs}})
+	}
+	if len(source.Keywords) > 0 {
+		// Keywords are stored as entered, so match them the way scoring compares them
+		var keywords bson.A
+		for _, keyword := range utils.NormalizeKeywords(source.Keywords) {
+			if keyword == "" {
+				continue
+			}
+			keywords = append(keywords, bson.Regex{Pattern: `^\s*` + regexp.QuoteMeta(keyword) + `\s*$`, Options: "i"})
+		}
+		if len(keywords) > 0 {
+			overlap = append(overlap, bson.M{"keywords": bson.M{"$in": keywords}})
+		}
+	}
+	if len(sourceFeatures.Cast) > 0 {
+		costarred, err := moviesWithCast(ctx, sourceFeatures.Cast)
+		if err != nil {
+			return nil, err
+		}
+		overlap = append(overlap, bson.M{"imdb_id": bson.M{"$in": costarred}})
+	}
+	if len(overlap) == 0 {
+		return []models.ScoredMovie{}, nil
+	}
+
+	filter := publicMovieFilter(bson.M{
+		"imdb_id": bson.M{"$ne": source.ImdbID},
+		"$or":     overlap,
+	})
+	// Keep the best ranked candidates when there are more than SIMILAR_CANDIDATE_LIMIT
+	opts := options.Find().
+		SetSort(bson.D{{Key: "ranking.ranking_value", Value: -1}, {Key: "imdb_id", Value: 1}}).
+		SetLimit(int64(utils.GetEnvInt("SIMILAR_CANDIDATE_LIMIT", 500)))
+	cursor, err := movieCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var candidates []models.Movie
+	if err = cursor.All(ctx, &candidates); err != nil {
+		return nil, err
+	}
+
+	candidateIds := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		candidateIds[i] = candidate.ImdbID
+	}
+	cast, err := movieCast(ctx, candidateIds)
+	if err != nil {
+		return nil, err
+	}
+
+	weights := utils.SimilarityWeightsFromEnv()
+	scored := make([]models.ScoredMovie, 0, len(candidates))
+	for _, candidate := range candidates {
+		candidateFeatures := movieFeatures(candidate)
+		candidateFeatures.Cast = cast[candidate.ImdbID]
+		score := utils.SimilarityScore(sourceFeatures, candidateFeatures, weights)
+		if score <= 0 {
+			continue
+		}
+		scored = append(scored, models.ScoredMovie{Movie: candidate, Score: math.Round(score*1000) / 1000})
+	}
+
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].Score > scored[j].Score
+	})
+	if len(scored) > limit {
+		scored = scored[:limit]
+	}
+	return scored, nil
+}
@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/database"
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/models"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var recommendationFeedbackCollection *mongo.Collection = database.OpenCollection("RecommendationFeedback")
+
+// recommendationFeedback is a user's feedback split by signal. Boosted movies are most recent first.
+type recommendationFeedback struct {
+	suppressed map[string]bool
+	boosted    []string
+}
+
+// SubmitRecommendationFeedback records "not_interested" or "more_like_this" for a movie.
+// A new signal for the same movie replaces the previous one.
+func SubmitRecommendationFeedback() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.RecommendationFeedbackInput
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+			return
+		}
+		if err := movieValidate.Struct(req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		exists, err := playableTitleExists(ctx, req.ImdbID, true)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to look up movie"})
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+			return
+		}
+
+		filter := bson.M{"user_id": c.GetString("userId"), "imdb_id": req.ImdbID}
+		update := bson.M{"$set": bson.M{"signal": req.Signal, "updated_at": time.Now()}}
+		if _, err := recommendationFeedbackCollection.UpdateOne(ctx, filter, update, options.UpdateOne().SetUpsert(true)); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save feedback"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Feedback recorded", "imdb_id": req.ImdbID, "signal": req.Signal})
+	}
+}
+
+// loadRecommendationFeedback returns the movies a user dismissed and the ones they asked for more of
+func loadRecommendationFeedback(ctx context.Context, userId string) (recommendationFeedback, error) {
+	feedback := recommendationFeedback{suppressed: map[string]bool{}}
+
+	opts := options.Find().SetSort(bson.D{{Key: "updated_at", Value: -1}})
+	cursor, err := recommendationFeedbackCollection.Find(ctx, bson.M{"user_id": userId}, opts)
+	if err != nil {
+		return feedback, err
+	}
+	var entries []models.RecommendationFeedback
+	if err = cursor.All(ctx, &entries); err != nil {
+		return feedback, err
+	}
+
+	for _, entry := range entries {
+		switch entry.Signal {
+		case "not_interested":
+			feedback.suppressed[entry.ImdbID] = true
+		case "more_like_this":
+			feedback.boosted = append(feedback.boosted, entry.ImdbID)
+		}
+	}
+	return feedback, nil
+}
+
+// genreReason explains a recommendation by the first of the movie's genres the user likes
+func genreReason(movie models.Movie, favourite map[string]bool) (models.RecommendationReason, bool) {
+	for _, genre := range movie.Genre {
+		if favourite[genre.GenreName] {
+			return models.RecommendationReason{
+				Type:  "genre",
+				Genre: genre.GenreName,
+				Text:  "because you like " + genre.GenreName,
+			}, true
+		}
+	}
+	return models.RecommendationReason{}, false
+}
+
+// similarReason explains a recommendation by the movie it resembles; boosted sources came from "more like this" feedback
+func similarReason(source models.Movie, boosted bool) models.RecommendationReason {
+	if boosted {
+		return models.RecommendationReason{
+			Type:   "more_like_this",
+			ImdbID: source.ImdbID,
+			Title:  source.Title,
+			Text:   "more like " + source.Title,
+		}
+	}
+	return models.RecommendationReason{
+		Type:   "similar_to",
+		ImdbID: source.ImdbID,
+		Title:  source.Title,
+		Text:   "similar to " + source.Title + " you watched",
+	}
+}
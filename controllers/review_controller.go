@@ -0,0 +1,299 @@
+package controllers
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/database"
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/models"
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var reviewCollection *mongo.Collection = database.OpenCollection("UserReview")
+var reviewChecker utils.ContentChecker = utils.NewContentChecker()
+
+// approvedReviewFilter matches reviews visible to the public. Reviews written before
+// moderation existed have no status and count as approved.
+func approvedReviewFilter(imdbId string) bson.M {
+	return bson.M{
+		"imdb_id": imdbId,
+		"$or": bson.A{
+			bson.M{"status": "approved"},
+			bson.M{"status": bson.M{"$exists": false}},
+		},
+	}
+}
+
+// UpsertReview creates or edits the authenticated user's review of a movie
+func UpsertReview() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		imdbId := c.Param("imdb_id")
+		userId := c.GetString("userId")
+
+		var req models.ReviewInput
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+			return
+		}
+		req.Text = strings.TrimSpace(req.Text)
+		if err := movieValidate.Struct(req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		exists, err := playableTitleExists(ctx, imdbId, true)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to look up movie"})
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+			return
+		}
+
+		user, err := getUserById(userId)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+
+		// Rating-only reviews have nothing to moderate; text goes through the content checks
+		// and anything they flag waits for a moderator
+		var flags []string
+		if req.Text != "" {
+			flags = reviewChecker.Check(req.Text)
+		}
+
+		// An edit that passes the checks is only approved automatically when the review was
+		// approved before. Rejected and reported reviews stay with the moderators, so the
+		// status is decided from the stored one inside the update itself.
+		var status any = "pending"
+		if len(flags) == 0 {
+			wasApproved := bson.M{"$eq": bson.A{bson.M{"$ifNull": bson.A{"$status", "approved"}}, "approved"}}
+			status = bson.M{"$cond": bson.A{wasApproved, "approved", "pending"}}
+		}
+		backToModeration := bson.M{"$eq": bson.A{"$status", "pending"}}
+
+		now := time.Now()
+		filter := bson.M{"imdb_id": imdbId, "user_id": userId}
+		update := bson.A{
+			// User input is wrapped in $literal so text starting with "$" is not read as a field path
+			bson.M{"$set": bson.M{
+				"user_name":     bson.M{"$literal": reviewerName(user)},
+				"rating":        req.Rating,
+				"text":          bson.M{"$literal": req.Text},
+				"status":        status,
+				"flags":         bson.M{"$literal": flags},
+				"updated_at":    now,
+				"created_at":    bson.M{"$ifNull": bson.A{"$created_at", now}},
+				"helpful_count": bson.M{"$ifNull": bson.A{"$helpful_count", 0}},
+			}},
+			// A resubmission waiting for a moderator starts without the reports against the old text
+			bson.M{"$set": bson.M{
+				"report_count": bson.M{"$cond": bson.A{backToModeration, 0, bson.M{"$ifNull": bson.A{"$report_count", 0}}}},
+				"reports":      bson.M{"$cond": bson.A{backToModeration, "$$REMOVE", "$reports"}},
+			}},
+			// An edit is a new submission, so earlier moderation no longer applies
+			bson.M{"$unset": bson.A{"moderated_by", "moderated_at", "moderation_note"}},
+		}
+
+		var review models.UserReview
+		opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+		if err := reviewCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&review); err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				c.JSON(http.StatusConflict, gin.H{"error": "Review was saved by another request, try again"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save review"})
+			return
+		}
+
+		if err := recomputeUserRating(ctx, imdbId); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Review saved but rating update failed"})
+			return
+		}
+
+		if review.Status == "pending" {
+			c.JSON(http.StatusAccepted, gin.H{"message": "Review submitted for moderation", "review": review})
+			return
+		}
+		c.JSON(http.StatusOK, review)
+	}
+}
+
+// DeleteReview removes the authenticated user's review of a movie
+func DeleteReview() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		imdbId := c.Param("imdb_id")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		result, err := reviewCollection.DeleteOne(ctx, bson.M{"imdb_id": imdbId, "user_id": c.GetString("userId")})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete review"})
+			return
+		}
+		if result.DeletedCount == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Review not found"})
+			return
+		}
+
+		if err := recomputeUserRating(ctx, imdbId); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Review deleted but rating update failed"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Review deleted successfully"})
+	}
+}
+
+// GetMovieReviews lists user reviews of a movie. ?sort=helpful|date (default date, newest first).
+func GetMovieReviews() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, limit, err := parsePagination(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		var sort bson.D
+		switch c.DefaultQuery("sort", "date") {
+		case "date":
+			sort = bson.D{{Key: "created_at", Value: -1}}
+		case "helpful":
+			sort = bson.D{{Key: "helpful_count", Value: -1}, {Key: "created_at", Value: -1}}
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "sort must be helpful or date"})
+			return
+		}
+
+		filter := approvedReviewFilter(c.Param("imdb_id"))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		total, err := reviewCollection.CountDocuments(ctx, filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		// Reporter identities, checker flags and moderator details are for moderators only
+		opts := options.Find().
+			SetSort(sort).
+			SetSkip((page - 1) * limit).
+			SetLimit(limit).
+			SetProjection(bson.M{"reports": 0, "flags": 0, "moderated_by": 0, "moderation_note": 0})
+		cursor, err := reviewCollection.Find(ctx, filter, opts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		defer cursor.Close(ctx)
+
+		reviews := []models.UserReview{}
+		if err = cursor.All(ctx, &reviews); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"reviews":     reviews,
+			"page":        page,
+			"limit":       limit,
+			"total_found": total,
+		})
+	}
+}
+
+// MarkReviewHelpful records the authenticated user's helpful vote on someone else's review.
+// Voting twice has no effect.
+func MarkReviewHelpful() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reviewId, err := bson.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid review ID"})
+			return
+		}
+		userId := c.GetString("userId")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		var review models.UserReview
+		if err := reviewCollection.FindOne(ctx, bson.M{"_id": reviewId}).Decode(&review); err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Review not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if review.UserID == userId {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot vote on your own review"})
+			return
+		}
+
+		filter := bson.M{"_id": reviewId, "helpful_voters": bson.M{"$ne": userId}}
+		update := bson.M{
+			"$addToSet": bson.M{"helpful_voters": userId},
+			"$inc":      bson.M{"helpful_count": 1},
+		}
+		if _, err := reviewCollection.UpdateOne(ctx, filter, update); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record vote"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Vote recorded"})
+	}
+}
+
+// recomputeUserRating refreshes the average and count of user ratings stored on the movie
+func recomputeUserRating(ctx context.Context, imdbId string) error {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: approvedReviewFilter(imdbId)}},
+		{{Key: "$group", Value: bson.M{
+			"_id":     nil,
+			"average": bson.M{"$avg": "$rating"},
+			"count":   bson.M{"$sum": 1},
+		}}},
+	}
+	cursor, err := reviewCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var results []models.UserRatingSummary
+	if err = cursor.All(ctx, &results); err != nil {
+		return err
+	}
+
+	update := bson.M{"$unset": bson.M{"user_rating": ""}}
+	if len(results) > 0 {
+		summary := results[0]
+		summary.Average = math.Round(summary.Average*10) / 10
+		update = bson.M{"$set": bson.M{"user_rating": summary}}
+	}
+	_, err = movieCollection.UpdateOne(ctx, bson.M{"imdb_id": imdbId}, update)
+	return err
+}
+
+// reviewerName is the public name shown on a review: first name and last initial
+func reviewerName(user *models.User) string {
+	if user.LastName == "" {
+		return user.FirstName
+	}
+	return user.FirstName + " " + string([]rune(user.LastName)[:1]) + "."
+}
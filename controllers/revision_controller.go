@@ -0,0 +1,343 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/database"
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/models"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var movieRevisionCollection *mongo.Collection = database.OpenCollection("MovieRevision")
+
+// untrackedMovieFields are maintained by the server rather than edited, so revisions ignore them.
+// Leaving out the metadata timestamp keeps refreshes that change nothing out of the history.
+var untrackedMovieFields = map[string]bool{
+	"_id":                 true,
+	"user_rating":         true,
+	"metadata_updated_at": true,
+	"version":             true,
+}
+
+// unrestorableMovieFields show up in the history but are never rolled back by a restore, so a
+// restore cannot publish or unpublish a movie around the publication workflow, nor move it
+// in or out of the trash
+var unrestorableMovieFields = map[string]bool{
+	"status":       true,
+	"publish_at":   true,
+	"published_at": true,
+	"deleted_at":   true,
+	"deleted_by":   true,
+}
+
+// movieFields returns the tracked top-level fields of a movie as stored in MongoDB
+func movieFields(movie *models.Movie) (bson.M, error) {
+	fields := bson.M{}
+	if movie == nil {
+		return fields, nil
+	}
+	data, err := bson.Marshal(movie)
+	if err != nil {
+		return nil, err
+	}
+	if err := bson.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for field := range untrackedMovieFields {
+		delete(fields, field)
+	}
+	return fields, nil
+}
+
+// diffMovies lists the tracked fields that differ between two versions of a movie.
+// A nil before stands for a movie that did not exist yet.
+func diffMovies(before, after *models.Movie) ([]models.FieldChange, error) {
+	beforeFields, err := movieFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := movieFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []models.FieldChange{}
+	for field, value := range afterFields {
+		if previous, ok := beforeFields[field]; !ok || !reflect.DeepEqual(previous, value) {
+			changes = append(changes, models.FieldChange{Field: field, Before: beforeFields[field], After: value})
+		}
+	}
+	for field, previous := range beforeFields {
+		if _, ok := afterFields[field]; !ok {
+			changes = append(changes, models.FieldChange{Field: field, Before: previous})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+	return changes, nil
+}
+
+// recordMovieRevision stores the difference between two versions of a movie as a revision numbered
+// with the version after the change. Versions only ever grow, so two changes never share a number;
+// changes to untracked fields alone are not stored, which can leave gaps.
+// revision carries who made the change and how.
+func recordMovieRevision(ctx context.Context, before, after *models.Movie, revision models.MovieRevision) error {
+	changes, err := diffMovies(before, after)
+	if err != nil || len(changes) == 0 {
+		return err
+	}
+
+	revision.ImdbID = after.ImdbID
+	revision.Revision = after.Version
+	revision.ChangedAt = time.Now()
+	revision.Changes = changes
+	_, err = movieRevisionCollection.InsertOne(ctx, revision)
+	return err
+}
+
+// checkMovieUpdate rejects updates that appliedUpdate cannot replay: anything other than
+// $set and $unset of top-level fields
+func checkMovieUpdate(update bson.M) error {
+	for operator, value := range update {
+		changed, ok := value.(bson.M)
+		if !ok || (operator != "$set" && operator != "$unset") {
+			return fmt.Errorf("unsupported movie update %s", operator)
+		}
+		for field := range changed {
+			if strings.Contains(field, ".") {
+				return fmt.Errorf("unsupported nested movie update %s", field)
+			}
+		}
+	}
+	return nil
+}
+
+// appliedUpdate returns the movie as a checked update leaves it, including the version bump.
+// Replaying the update instead of reading the document back keeps another writer's change out of the diff.
+func appliedUpdate(before models.Movie, update bson.M) (models.Movie, error) {
+	data, err := bson.Marshal(before)
+	if err != nil {
+		return models.Movie{}, err
+	}
+	fields := bson.M{}
+	if err := bson.Unmarshal(data, &fields); err != nil {
+		return models.Movie{}, err
+	}
+	if set, ok := update["$set"].(bson.M); ok {
+		for field, value := range set {
+			fields[field] = value
+		}
+	}
+	if unset, ok := update["$unset"].(bson.M); ok {
+		for field := range unset {
+			delete(fields, field)
+		}
+	}
+
+	if data, err = bson.Marshal(fields); err != nil {
+		return models.Movie{}, err
+	}
+	var after models.Movie
+	if err := bson.Unmarshal(data, &after); err != nil {
+		return models.Movie{}, err
+	}
+	after.Version = before.Version + 1
+	return after, nil
+}
+
+// updateMovie applies update to the movie matching filter, bumps its version, records the change as
+// a revision and returns the updated movie. update may only $set and $unset top-level fields.
+// It returns mongo.ErrNoDocuments when no movie matches. A revision that fails to save is logged
+// rather than failing an update that already happened.
+func updateMovie(ctx context.Context, filter, update bson.M, revision models.MovieRevision) (models.Movie, error) {
+	if err := checkMovieUpdate(update); err != nil {
+		return models.Movie{}, err
+	}
+
+	var before models.Movie
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.Before)
+	if err := movieCollection.FindOneAndUpdate(ctx, filter, bumpVersion(update), opts).Decode(&before); err != nil {
+		return models.Movie{}, err
+	}
+
+	after, err := appliedUpdate(before, update)
+	if err != nil {
+		return models.Movie{}, err
+	}
+
+	if err := recordMovieRevision(ctx, &before, &after, revision); err != nil {
+		log.Printf("Failed to record revision of movie %s: %v", after.ImdbID, err)
+	}
+	return after, nil
+}
+
+// GetMovieRevisions lists a movie's revisions, newest first
+func GetMovieRevisions() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		imdbId := c.Param("imdb_id")
+
+		page, limit, err := parsePagination(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		filter := bson.M{"imdb_id": imdbId}
+		total, err := movieRevisionCollection.CountDocuments(ctx, filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		opts := options.Find().
+			SetSort(bson.D{{Key: "revision", Value: -1}}).
+			SetSkip((page - 1) * limit).
+			SetLimit(limit)
+		cursor, err := movieRevisionCollection.Find(ctx, filter, opts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		revisions := []models.MovieRevision{}
+		if err = cursor.All(ctx, &revisions); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"revisions":   revisions,
+			"page":        page,
+			"limit":       limit,
+			"total_found": total,
+		})
+	}
+}
+
+// RestoreMovieRevision rolls a movie back to how it looked right after the given revision by
+// undoing every later revision. The rollback is itself recorded as a new revision.
+func RestoreMovieRevision() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		imdbId := c.Param("imdb_id")
+		rev, err := strconv.Atoi(c.Param("rev"))
+		if err != nil || rev < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision"})
+			return
+		}
+		version, ok := ifMatchVersion(c)
+		if !ok {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		count, err := movieRevisionCollection.CountDocuments(ctx, bson.M{"imdb_id": imdbId, "revision": rev})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if count == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Revision not found"})
+			return
+		}
+
+		var movie models.Movie
+		if err := movieCollection.FindOne(ctx, liveMovieFilter(bson.M{"imdb_id": imdbId})).Decode(&movie); err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if !matchLoadedVersion(c, movie, version) {
+			return
+		}
+
+		opts := options.Find().SetSort(bson.D{{Key: "revision", Value: -1}})
+		cursor, err := movieRevisionCollection.Find(ctx, bson.M{"imdb_id": imdbId, "revision": bson.M{"$gt": rev}}, opts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		var later []models.MovieRevision
+		if err = cursor.All(ctx, &later); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		// Undo the later revisions, newest first, to work out which fields to put back
+		current, err := movieFields(&movie)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		target := bson.M{}
+		for field, value := range current {
+			target[field] = value
+		}
+		for _, revision := range later {
+			for _, change := range revision.Changes {
+				if unrestorableMovieFields[change.Field] {
+					continue
+				}
+				if change.Before == nil {
+					delete(target, change.Field)
+				} else {
+					target[change.Field] = change.Before
+				}
+			}
+		}
+
+		set := bson.M{}
+		unset := bson.M{}
+		for field, value := range target {
+			if !reflect.DeepEqual(current[field], value) {
+				set[field] = value
+			}
+		}
+		for field := range current {
+			if _, ok := target[field]; !ok {
+				unset[field] = ""
+			}
+		}
+		if len(set) == 0 && len(unset) == 0 {
+			c.Header("ETag", movieETag(movie.Version))
+			c.JSON(http.StatusOK, gin.H{"message": "Movie already matches this revision", "movie": movie})
+			return
+		}
+
+		update := bson.M{}
+		if len(set) > 0 {
+			update["$set"] = set
+		}
+		if len(unset) > 0 {
+			update["$unset"] = unset
+		}
+		restored, ok := updateMovieIfMatch(ctx, c, liveMovieFilter(bson.M{"_id": movie.ID}), update, movie.Version, models.MovieRevision{
+			ChangedBy:    c.GetString("userId"),
+			Source:       "restore",
+			RestoredFrom: rev,
+		})
+		if !ok {
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Movie restored", "movie": restored})
+	}
+}
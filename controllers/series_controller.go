@@ -0,0 +1,411 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/database"
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/models"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var seriesCollection *mongo.Collection = database.OpenCollection("Series")
+var episodeCollection *mongo.Collection = database.OpenCollection("Episode")
+
+// GetSeries returns a series with its seasons
+func GetSeries() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		var series models.Series
+		if err := seriesCollection.FindOne(ctx, bson.M{"series_id": c.Param("id")}).Decode(&series); err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Series not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, series)
+	}
+}
+
+// GetSeasonEpisodes lists the episodes of one season in order
+func GetSeasonEpisodes() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		seriesId := c.Param("id")
+		seasonNumber, err := strconv.Atoi(c.Param("n"))
+		if err != nil || seasonNumber < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Season number must be a positive integer"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		count, err := seriesCollection.CountDocuments(ctx, bson.M{"series_id": seriesId, "seasons.number": seasonNumber})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if count == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Season not found"})
+			return
+		}
+
+		opts := options.Find().SetSort(bson.D{{Key: "episode_number", Value: 1}})
+		cursor, err := episodeCollection.Find(ctx, bson.M{"series_id": seriesId, "season_number": seasonNumber}, opts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		defer cursor.Close(ctx)
+
+		episodes := []models.Episode{}
+		if err = cursor.All(ctx, &episodes); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"series_id":     seriesId,
+			"season_number": seasonNumber,
+			"episodes":      episodes,
+			"total_found":   len(episodes),
+		})
+	}
+}
+
+// CreateSeries adds a series. Seasons may be included or added later.
+func CreateSeries() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var series models.Series
+		if err := c.ShouldBindJSON(&series); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
+			return
+		}
+		if err := movieValidate.Struct(series); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+			return
+		}
+
+		genres, ok := requireCatalogGenres(c, series.Genre)
+		if !ok {
+			return
+		}
+		series.Genre = genres
+
+		seen := map[int]bool{}
+		for _, season := range series.Seasons {
+			if seen[season.Number] {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Duplicate season number " + strconv.Itoa(season.Number)})
+				return
+			}
+			seen[season.Number] = true
+		}
+		if series.Seasons == nil {
+			series.Seasons = []models.Season{}
+		}
+		sort.Slice(series.Seasons, func(i, j int) bool {
+			return series.Seasons[i].Number < series.Seasons[j].Number
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		// Series IDs are unique, enforced by a unique index
+		series.ID = bson.ObjectID{}
+		series.CreatedAt = time.Now()
+		if _, err := seriesCollection.InsertOne(ctx, series); err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				c.JSON(http.StatusConflict, gin.H{"error": "Series already exists"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{"message": "Series created successfully", "series_id": series.SeriesID})
+	}
+}
+
+// AddSeason appends a season to a series
+func AddSeason() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		seriesId := c.Param("id")
+
+		var season models.Season
+		if err := c.ShouldBindJSON(&season); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
+			return
+		}
+		if err := movieValidate.Struct(season); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		// The filter only matches while the season number is free, so concurrent adds cannot duplicate it
+		filter := bson.M{"series_id": seriesId, "seasons.number": bson.M{"$ne": season.Number}}
+		update := bson.M{"$push": bson.M{"seasons": bson.M{
+			"$each": bson.A{season},
+			"$sort": bson.M{"number": 1},
+		}}}
+		result, err := seriesCollection.UpdateOne(ctx, filter, update)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if result.MatchedCount == 0 {
+			count, err := seriesCollection.CountDocuments(ctx, bson.M{"series_id": seriesId})
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			if count == 0 {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Series not found"})
+				return
+			}
+			c.JSON(http.StatusConflict, gin.H{"error": "Season already exists"})
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{"message": "Season added", "series_id": seriesId, "season_number": season.Number})
+	}
+}
+
+// AddEpisode adds an episode to an existing season. The episode's IMDb ID must not already
+// be used by a movie or another episode since it keys the episode's media.
+func AddEpisode() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		seriesId := c.Param("id")
+
+		var episode models.Episode
+		if err := c.ShouldBindJSON(&episode); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
+			return
+		}
+		if err := movieValidate.Struct(episode); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		count, err := seriesCollection.CountDocuments(ctx, bson.M{"series_id": seriesId, "seasons.number": episode.SeasonNumber})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if count == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Season not found"})
+			return
+		}
+
+		taken, err := playableTitleExists(ctx, episode.ImdbID, false)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if taken {
+			c.JSON(http.StatusConflict, gin.H{"error": "IMDb ID is already in use"})
+			return
+		}
+
+		// Episode numbers and episode IMDb IDs are unique, enforced by unique indexes
+		episode.ID = bson.ObjectID{}
+		episode.SeriesID = seriesId
+		episode.CreatedAt = time.Now()
+		if _, err := episodeCollection.InsertOne(ctx, episode); err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				c.JSON(http.StatusConflict, gin.H{"error": "Episode already exists or its IMDb ID is already in use"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{"message": "Episode added", "episode": episode})
+	}
+}
+
+// GetEpisodeAfter returns the episode that follows an episode, for autoplay at the end of playback
+func GetEpisodeAfter() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		var current models.Episode
+		if err := episodeCollection.FindOne(ctx, bson.M{"imdb_id": c.Param("imdb_id")}).Decode(&current); err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Episode not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		next, err := episodeAfter(ctx, current)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		result := models.NextEpisode{SeriesID: current.SeriesID, Status: "next", Episode: next}
+		if next == nil {
+			result.Status = "completed"
+		}
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// GetNextEpisode tells the authenticated user's player what to play in a series: the first episode
+// if nothing was watched, the last played episode if it is unfinished, otherwise the one after it
+func GetNextEpisode() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		seriesId := c.Param("id")
+		userId := c.GetString("userId")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		opts := options.Find().SetSort(bson.D{{Key: "season_number", Value: 1}, {Key: "episode_number", Value: 1}})
+		cursor, err := episodeCollection.Find(ctx, bson.M{"series_id": seriesId}, opts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		defer cursor.Close(ctx)
+
+		var episodes []models.Episode
+		if err = cursor.All(ctx, &episodes); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if len(episodes) == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Series has no episodes"})
+			return
+		}
+
+		imdbIds := make([]string, len(episodes))
+		for i, episode := range episodes {
+			imdbIds[i] = episode.ImdbID
+		}
+		progress, watched, err := latestProgress(ctx, userId, imdbIds)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		result := models.NextEpisode{SeriesID: seriesId, Status: "start", Episode: &episodes[0]}
+		if watched {
+			for i := range episodes {
+				if episodes[i].ImdbID != progress.ImdbID {
+					continue
+				}
+				switch {
+				case !progress.Finished:
+					result.Status = "resume"
+					result.Episode = &episodes[i]
+					result.PositionSeconds = progress.PositionSeconds
+				case i+1 < len(episodes):
+					result.Status = "next"
+					result.Episode = &episodes[i+1]
+				default:
+					result.Status = "completed"
+					result.Episode = nil
+				}
+				break
+			}
+		}
+
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// episodeAfter finds the next episode in the same season, or the first episode of the following season
+func episodeAfter(ctx context.Context, current models.Episode) (*models.Episode, error) {
+	filter := bson.M{
+		"series_id": current.SeriesID,
+		"$or": bson.A{
+			bson.M{"season_number": current.SeasonNumber, "episode_number": bson.M{"$gt": current.EpisodeNumber}},
+			bson.M{"season_number": bson.M{"$gt": current.SeasonNumber}},
+		},
+	}
+	opts := options.FindOne().SetSort(bson.D{{Key: "season_number", Value: 1}, {Key: "episode_number", Value: 1}})
+
+	var next models.Episode
+	if err := episodeCollection.FindOne(ctx, filter, opts).Decode(&next); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &next, nil
+}
+
+// findEpisodesByImdbIDs loads the episodes with the given IMDb IDs, with their series and season,
+// keyed by IMDb ID. Episodes whose series no longer exists are left out.
+func findEpisodesByImdbIDs(ctx context.Context, imdbIds []string) (map[string]models.WatchedEpisode, error) {
+	watched := map[string]models.WatchedEpisode{}
+	if len(imdbIds) == 0 {
+		return watched, nil
+	}
+
+	cursor, err := episodeCollection.Find(ctx, bson.M{"imdb_id": bson.M{"$in": imdbIds}})
+	if err != nil {
+		return nil, err
+	}
+	var episodes []models.Episode
+	if err = cursor.All(ctx, &episodes); err != nil {
+		return nil, err
+	}
+	if len(episodes) == 0 {
+		return watched, nil
+	}
+
+	var seriesIds []string
+	for _, episode := range episodes {
+		seriesIds = append(seriesIds, episode.SeriesID)
+	}
+	cursor, err = seriesCollection.Find(ctx, bson.M{"series_id": bson.M{"$in": seriesIds}})
+	if err != nil {
+		return nil, err
+	}
+	var seriesList []models.Series
+	if err = cursor.All(ctx, &seriesList); err != nil {
+		return nil, err
+	}
+	seriesById := map[string]models.Series{}
+	for _, series := range seriesList {
+		seriesById[series.SeriesID] = series
+	}
+
+	for _, episode := range episodes {
+		series, ok := seriesById[episode.SeriesID]
+		if !ok {
+			continue
+		}
+		item := models.WatchedEpisode{Episode: episode, Series: series}
+		for _, season := range series.Seasons {
+			if season.Number == episode.SeasonNumber {
+				item.Season = &season
+				break
+			}
+		}
+		item.Series.Seasons = nil
+		watched[episode.ImdbID] = item
+	}
+	return watched, nil
+}
@@ -0,0 +1,217 @@
+package controllers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/database"
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/models"
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var subtitleCollection *mongo.Collection = database.OpenCollection("Subtitle")
+
+const subtitleDirName = "subtitles"
+
+// UploadSubtitle stores a caption track for a title. SRT is converted to WebVTT; both
+// formats have their cue timings validated before anything is written.
+func UploadSubtitle() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		imdbId := c.Param("imdb_id")
+
+		var form models.SubtitleUpload
+		if err := c.ShouldBind(&form); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid form data"})
+			return
+		}
+		if err := movieValidate.Struct(form); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": err.Error()})
+			return
+		}
+
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Subtitle file is required"})
+			return
+		}
+		if fileHeader.Size > int64(utils.GetEnvInt("SUBTITLE_MAX_BYTES", 2<<20)) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Subtitle file is too large"})
+			return
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read subtitle file"})
+			return
+		}
+		defer file.Close()
+		data, err := io.ReadAll(file)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read subtitle file"})
+			return
+		}
+
+		sourceFormat := "srt"
+		if utils.IsWebVTT(data) {
+			sourceFormat = "vtt"
+		} else if !strings.EqualFold(filepath.Ext(fileHeader.Filename), ".srt") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Only SRT and WebVTT subtitles are supported"})
+			return
+		}
+
+		cues, err := utils.ParseSubtitles(data)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid subtitle timing", "details": err.Error()})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		exists, err := playableTitleExists(ctx, imdbId, false)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to look up movie"})
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+			return
+		}
+
+		language := form.Language
+		label := form.Label
+		if label == "" {
+			label = language
+		}
+		// Cues may overlap or arrive out of order, so the track lasts until the latest end
+		var duration float64
+		for _, cue := range cues {
+			duration = max(duration, cue.End)
+		}
+
+		dir := filepath.Join(mediaDir(imdbId), subtitleDirName)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store subtitle"})
+			return
+		}
+		if err := os.WriteFile(filepath.Join(dir, language+".vtt"), utils.WriteWebVTT(cues), 0o644); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store subtitle"})
+			return
+		}
+		if err := os.WriteFile(filepath.Join(dir, language+".m3u8"), utils.SubtitlePlaylist(language+".vtt", duration), 0o644); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store subtitle"})
+			return
+		}
+
+		now := time.Now()
+		filter := bson.M{"imdb_id": imdbId, "language": language}
+		update := bson.M{
+			"$set": bson.M{
+				"label":            label,
+				"source_format":    sourceFormat,
+				"cue_count":        len(cues),
+				"duration_seconds": duration,
+				"path":             subtitleDirName + "/" + language + ".vtt",
+				"updated_at":       now,
+			},
+			"$setOnInsert": bson.M{"created_at": now},
+		}
+
+		var subtitle models.Subtitle
+		opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+		if err := subtitleCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&subtitle); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save subtitle"})
+			return
+		}
+
+		c.JSON(http.StatusCreated, subtitle)
+	}
+}
+
+// GetSubtitles lists the caption tracks available for a title
+func GetSubtitles() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		subtitles, err := findSubtitles(ctx, c.Param("imdb_id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"subtitles":   subtitles,
+			"total_found": len(subtitles),
+		})
+	}
+}
+
+// DeleteSubtitle removes a caption track and its files
+func DeleteSubtitle() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		imdbId := c.Param("imdb_id")
+		language := c.Param("language")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		result, err := subtitleCollection.DeleteOne(ctx, bson.M{"imdb_id": imdbId, "language": language})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete subtitle"})
+			return
+		}
+		if result.DeletedCount == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Subtitle not found"})
+			return
+		}
+
+		dir := filepath.Join(mediaDir(imdbId), subtitleDirName)
+		os.Remove(filepath.Join(dir, filepath.Base(language)+".vtt"))
+		os.Remove(filepath.Join(dir, filepath.Base(language)+".m3u8"))
+
+		c.JSON(http.StatusOK, gin.H{"message": "Subtitle deleted successfully"})
+	}
+}
+
+func findSubtitles(ctx context.Context, imdbId string) ([]models.Subtitle, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "language", Value: 1}})
+	cursor, err := subtitleCollection.Find(ctx, bson.M{"imdb_id": imdbId}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	subtitles := []models.Subtitle{}
+	if err = cursor.All(ctx, &subtitles); err != nil {
+		return nil, err
+	}
+	return subtitles, nil
+}
+
+// subtitleRenditions turns a title's caption tracks into master playlist entries
+func subtitleRenditions(ctx context.Context, imdbId string) ([]utils.SubtitleRendition, error) {
+	subtitles, err := findSubtitles(ctx, imdbId)
+	if err != nil {
+		return nil, err
+	}
+
+	renditions := make([]utils.SubtitleRendition, 0, len(subtitles))
+	for _, subtitle := range subtitles {
+		renditions = append(renditions, utils.SubtitleRendition{
+			Language: subtitle.Language,
+			Name:     strings.ReplaceAll(subtitle.Label, `"`, "'"),
+			URI:      subtitleDirName + "/" + subtitle.Language + ".m3u8",
+		})
+	}
+	return renditions, nil
+}
@@ -0,0 +1,218 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/models"
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const thumbnailDirName = "thumbnails"
+const thumbnailTrackName = "thumbnails.vtt"
+
+// Sprite generation is slow, so requests are queued and handled one at a time by StartThumbnailWorker
+var thumbnailQueue = make(chan string, 100)
+
+// StartThumbnailWorker processes queued scrub-preview jobs in the background
+func StartThumbnailWorker() {
+	resetInterruptedThumbnails()
+
+	go func() {
+		for imdbId := range thumbnailQueue {
+			setThumbnailStatus(imdbId, "generating", "")
+			if err := generateThumbnails(imdbId); err != nil {
+				log.Printf("Thumbnail generation for %s failed: %v", imdbId, err)
+				setThumbnailStatus(imdbId, "failed", err.Error())
+				continue
+			}
+			setThumbnailStatus(imdbId, "ready", "")
+		}
+	}()
+}
+
+// enqueueThumbnails schedules sprite generation, returning false when the queue is full
+func enqueueThumbnails(imdbId string) bool {
+	// Record the status before handing off so the worker's "generating" always lands after it
+	setThumbnailStatus(imdbId, "queued", "")
+	select {
+	case thumbnailQueue <- imdbId:
+		return true
+	default:
+		setThumbnailStatus(imdbId, "failed", "thumbnail queue is full")
+		return false
+	}
+}
+
+// resetInterruptedThumbnails marks jobs left queued or generating by a previous process as failed.
+// The queue lives in memory, so those jobs will never run and would otherwise block new requests.
+func resetInterruptedThumbnails() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"thumbnail_status": bson.M{"$in": bson.A{"queued", "generating"}}}
+	update := bson.M{"$set": bson.M{"thumbnail_status": "failed", "thumbnail_error": "interrupted by a server restart"}}
+	result, err := mediaAssetCollection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		log.Printf("Failed to reset interrupted thumbnail jobs: %v", err)
+		return
+	}
+	if result.ModifiedCount > 0 {
+		log.Printf("Marked %d interrupted thumbnail jobs as failed", result.ModifiedCount)
+	}
+}
+
+func setThumbnailStatus(imdbId, status, errorMessage string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	update := bson.M{"$set": bson.M{"thumbnail_status": status, "thumbnail_error": errorMessage}}
+	if _, err := mediaAssetCollection.UpdateOne(ctx, bson.M{"imdb_id": imdbId}, update); err != nil {
+		log.Printf("Failed to record thumbnail status for %s: %v", imdbId, err)
+	}
+}
+
+// GenerateThumbnails queues scrub-preview sprite generation for a packaged title
+func GenerateThumbnails() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		imdbId := c.Param("imdb_id")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		var asset models.MediaAsset
+		err := mediaAssetCollection.FindOne(ctx, bson.M{"imdb_id": imdbId}).Decode(&asset)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Movie has no video asset"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if asset.ThumbnailStatus == "queued" || asset.ThumbnailStatus == "generating" {
+			c.JSON(http.StatusConflict, gin.H{"error": "Thumbnails are already being generated"})
+			return
+		}
+
+		if !enqueueThumbnails(imdbId) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Thumbnail queue is full, try again later"})
+			return
+		}
+
+		c.JSON(http.StatusAccepted, gin.H{"message": "Thumbnail generation queued", "imdb_id": imdbId})
+	}
+}
+
+// generateThumbnails extracts frames at a fixed interval with the external tool, tiles them into
+// sprite sheets and writes a WebVTT thumbnails track with #xywh fragments
+func generateThumbnails(imdbId string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	var asset models.MediaAsset
+	err := mediaAssetCollection.FindOne(ctx, bson.M{"imdb_id": imdbId}).Decode(&asset)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	source, ok := sourcePath(asset.SourcePath)
+	if !ok {
+		return fmt.Errorf("invalid source path %q", asset.SourcePath)
+	}
+
+	interval := utils.GetEnvInt("THUMBNAIL_INTERVAL_SECONDS", 10)
+	width := utils.GetEnvInt("THUMBNAIL_WIDTH", 160)
+	columns := utils.GetEnvInt("THUMBNAIL_SPRITE_COLUMNS", 10)
+	rows := utils.GetEnvInt("THUMBNAIL_SPRITE_ROWS", 10)
+	if interval <= 0 || width <= 0 || columns <= 0 || rows <= 0 {
+		return fmt.Errorf("thumbnail settings must be positive")
+	}
+
+	framesDir, err := os.MkdirTemp("", "thumbnails-"+imdbId+"-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(framesDir)
+
+	extractCtx, cancelExtract := context.WithTimeout(context.Background(), utils.GetEnvDuration("PACKAGING_TIMEOUT", 2*time.Hour))
+	defer cancelExtract()
+
+	cmd := exec.CommandContext(extractCtx, ffmpegPath,
+		"-y", "-i", source,
+		"-vf", "fps=1/"+strconv.Itoa(interval)+",scale="+strconv.Itoa(width)+":-2",
+		"-q:v", "5",
+		filepath.Join(framesDir, "frame_%05d.jpg"),
+	)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("frame extraction failed: %v: %s", err, lastLines(string(output), 5))
+	}
+
+	framePaths, err := filepath.Glob(filepath.Join(framesDir, "frame_*.jpg"))
+	if err != nil {
+		return err
+	}
+	if len(framePaths) == 0 {
+		return fmt.Errorf("no frames were extracted")
+	}
+
+	outDir := filepath.Join(mediaDir(imdbId), thumbnailDirName)
+	if err := os.RemoveAll(outDir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return err
+	}
+
+	perSprite := columns * rows
+	quality := utils.GetEnvInt("POSTER_JPEG_QUALITY", 82)
+	var cues []utils.Cue
+	for start := 0; start < len(framePaths); start += perSprite {
+		end := min(start+perSprite, len(framePaths))
+		frames := make([]image.Image, 0, end-start)
+		for _, path := range framePaths[start:end] {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			frame, _, err := utils.DecodeImage(data)
+			if err != nil {
+				return fmt.Errorf("%s: %v", filepath.Base(path), err)
+			}
+			frames = append(frames, frame)
+		}
+
+		spriteName := fmt.Sprintf("sprite_%03d.jpg", start/perSprite)
+		encoded, err := utils.EncodeJPEG(utils.TileSprite(frames, columns), quality)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(outDir, spriteName), encoded, 0o644); err != nil {
+			return err
+		}
+
+		frameWidth := frames[0].Bounds().Dx()
+		frameHeight := frames[0].Bounds().Dy()
+		for i := range frames {
+			n := start + i
+			cues = append(cues, utils.Cue{
+				Start: float64(n * interval),
+				End:   float64((n + 1) * interval),
+				Text: fmt.Sprintf("%s#xywh=%d,%d,%d,%d", spriteName,
+					(i%columns)*frameWidth, (i/columns)*frameHeight, frameWidth, frameHeight),
+			})
+		}
+	}
+
+	return os.WriteFile(filepath.Join(outDir, thumbnailTrackName), utils.WriteWebVTT(cues), 0o644)
+}
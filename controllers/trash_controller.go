@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/models"
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// trashRetention is how long deleted movies stay restorable. Zero keeps them forever.
+func trashRetention() time.Duration {
+	return time.Duration(utils.GetEnvInt("TRASH_RETENTION_DAYS", 30)) * 24 * time.Hour
+}
+
+// GetTrash lists deleted movies, most recently deleted first, with the time each will be purged
+func GetTrash() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, limit, err := parsePagination(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		filter := bson.M{"deleted_at": bson.M{"$exists": true}}
+		total, err := movieCollection.CountDocuments(ctx, filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		opts := options.Find().
+			SetSort(bson.D{{Key: "deleted_at", Value: -1}}).
+			SetSkip((page - 1) * limit).
+			SetLimit(limit)
+		cursor, err := movieCollection.Find(ctx, filter, opts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		var movies []models.Movie
+		if err = cursor.All(ctx, &movies); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		type trashedMovie struct {
+			models.Movie
+			PurgeAt *time.Time `json:"purge_at,omitempty"`
+		}
+		retention := trashRetention()
+		trashed := make([]trashedMovie, len(movies))
+		for i, movie := range movies {
+			trashed[i] = trashedMovie{Movie: movie}
+			if retention > 0 {
+				purgeAt := movie.DeletedAt.Add(retention)
+				trashed[i].PurgeAt = &purgeAt
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"movies":      trashed,
+			"page":        page,
+			"limit":       limit,
+			"total_found": total,
+		})
+	}
+}
+
+// RestoreFromTrash brings a deleted movie back with its watchlist entries, reviews and credits intact
+func RestoreFromTrash() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		imdbId := c.Param("imdb_id")
+		version, ok := ifMatchVersion(c)
+		if !ok {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		filter := bson.M{"imdb_id": imdbId, "deleted_at": bson.M{"$exists": true}}
+		update := bson.M{"$unset": bson.M{"deleted_at": "", "deleted_by": ""}}
+		revision := models.MovieRevision{ChangedBy: c.GetString("userId"), Source: "restore_trash"}
+		restored, ok := updateMovieIfMatch(ctx, c, filter, update, version, revision)
+		if !ok {
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Movie restored from trash", "movie": restored})
+	}
+}
+
+// StartTrashPurgeJob periodically deletes movies that have been in the trash longer than
+// TRASH_RETENTION_DAYS, along with everything that still refers to them
+func StartTrashPurgeJob() {
+	retention := trashRetention()
+	if retention <= 0 {
+		return
+	}
+	interval := utils.GetEnvDuration("TRASH_PURGE_INTERVAL", time.Hour)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			purgeTrash(retention)
+			<-ticker.C
+		}
+	}()
+}
+
+func purgeTrash(retention time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	cutoff := time.Now().Add(-retention)
+	opts := options.Find().SetProjection(bson.M{"imdb_id": 1})
+	cursor, err := movieCollection.Find(ctx, bson.M{"deleted_at": bson.M{"$lt": cutoff}}, opts)
+	if err != nil {
+		log.Printf("Trash purge failed: %v", err)
+		return
+	}
+	var movies []models.Movie
+	if err = cursor.All(ctx, &movies); err != nil {
+		log.Printf("Trash purge failed: %v", err)
+		return
+	}
+
+	purged := 0
+	for _, movie := range movies {
+		// References go first and the movie last, so a failed cleanup leaves the movie
+		// in the trash for the next run instead of orphaning its references
+		if err := removeMovieReferences(ctx, movie.ImdbID); err != nil {
+			log.Printf("Trash purge of %s failed: %v", movie.ImdbID, err)
+			continue
+		}
+		// The filter is checked again so a movie restored meanwhile survives
+		result, err := movieCollection.DeleteOne(ctx, bson.M{"_id": movie.ID, "deleted_at": bson.M{"$lt": cutoff}})
+		if err != nil {
+			log.Printf("Trash purge of %s failed: %v", movie.ImdbID, err)
+			continue
+		}
+		if result.DeletedCount > 0 {
+			purged++
+		}
+	}
+	if purged > 0 {
+		log.Printf("Trash purge deleted %d movies deleted before %s", purged, cutoff.Format(time.RFC3339))
+	}
+}
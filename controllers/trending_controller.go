@@ -0,0 +1,218 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/models"
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// trendingWindows are the supported ranking windows
+var trendingWindows = map[string]time.Duration{
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+}
+
+// trendingCache holds the latest ranking per window so the endpoint never aggregates on the request path
+var trendingCache = struct {
+	sync.RWMutex
+	rankings    map[string][]models.TrendingMovie
+	refreshedAt map[string]time.Time
+}{
+	rankings:    map[string][]models.TrendingMovie{},
+	refreshedAt: map[string]time.Time{},
+}
+
+// StartTrendingAggregator refreshes the trending rankings of every window in the background
+func StartTrendingAggregator() {
+	interval := utils.GetEnvDuration("TRENDING_REFRESH_INTERVAL", 15*time.Minute)
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			for window := range trendingWindows {
+				if err := refreshTrending(window); err != nil {
+					log.Printf("Trending refresh for %s failed: %v", window, err)
+				}
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// GetTrendingMovies returns the cached trending ranking for window=24h|7d|30d
+func GetTrendingMovies() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		window := c.DefaultQuery("window", "7d")
+		if _, ok := trendingWindows[window]; !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "window must be one of 24h, 7d, 30d"})
+			return
+		}
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+		if err != nil || limit < 1 || limit > 100 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 100"})
+			return
+		}
+
+		trendingCache.RLock()
+		ranking, cached := trendingCache.rankings[window]
+		refreshedAt := trendingCache.refreshedAt[window]
+		trendingCache.RUnlock()
+
+		// Only the first request after startup, before the aggregator's first pass, pays for the aggregation
+		if !cached {
+			if err := refreshTrending(window); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			trendingCache.RLock()
+			ranking = trendingCache.rankings[window]
+			refreshedAt = trendingCache.refreshedAt[window]
+			trendingCache.RUnlock()
+		}
+
+		if len(ranking) > limit {
+			ranking = ranking[:limit]
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"window":          window,
+			"trending_movies": ranking,
+			"total_found":     len(ranking),
+			"refreshed_at":    refreshedAt,
+		})
+	}
+}
+
+// trendingActivity is the decayed activity of one movie from one event source
+type trendingActivity struct {
+	ImdbID string  `bson:"_id"`
+	Score  float64 `bson:"score"`
+	Events int     `bson:"events"`
+}
+
+// refreshTrending recomputes and caches the ranking for a window. Each view, watchlist add and
+// rating in the window contributes its source weight, halved every TRENDING_HALF_LIFE_RATIO of the window.
+func refreshTrending(window string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	span := trendingWindows[window]
+	halfLife := time.Duration(float64(span) * utils.GetEnvFloat("TRENDING_HALF_LIFE_RATIO", 0.25))
+	if halfLife <= 0 {
+		halfLife = span
+	}
+	now := time.Now()
+	since := now.Add(-span)
+
+	sources := []struct {
+		collection *mongo.Collection
+		timeField  string
+		filter     bson.M
+		weight     float64
+		count      func(entry *models.TrendingMovie) *int
+	}{
+		{historyCollection, "started_at", bson.M{}, utils.GetEnvFloat("TRENDING_WEIGHT_VIEW", 1),
+			func(entry *models.TrendingMovie) *int { return &entry.Views }},
+		{watchlistCollection, "added_at", bson.M{}, utils.GetEnvFloat("TRENDING_WEIGHT_WATCHLIST", 2),
+			func(entry *models.TrendingMovie) *int { return &entry.WatchlistAdds }},
+		{reviewCollection, "created_at", bson.M{"status": bson.M{"$ne": "rejected"}}, utils.GetEnvFloat("TRENDING_WEIGHT_RATING", 3),
+			func(entry *models.TrendingMovie) *int { return &entry.Ratings }},
+	}
+
+	scores := map[string]*models.TrendingMovie{}
+	for _, source := range sources {
+		activity, err := decayedActivity(ctx, source.collection, source.timeField, source.filter, since, now, halfLife)
+		if err != nil {
+			return err
+		}
+		for _, row := range activity {
+			entry, ok := scores[row.ImdbID]
+			if !ok {
+				entry = &models.TrendingMovie{}
+				scores[row.ImdbID] = entry
+			}
+			entry.Score += source.weight * row.Score
+			*source.count(entry) = row.Events
+		}
+	}
+
+	ids := make([]string, 0, len(scores))
+	for imdbId := range scores {
+		ids = append(ids, imdbId)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return scores[ids[i]].Score > scores[ids[j]].Score
+	})
+	if maxSize := utils.GetEnvInt("TRENDING_CACHE_SIZE", 100); len(ids) > maxSize {
+		ids = ids[:maxSize]
+	}
+
+	movies, err := findMoviesByImdbIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	ranking := make([]models.TrendingMovie, 0, len(ids))
+	for _, imdbId := range ids {
+		movie, ok := movies[imdbId]
+		if !ok {
+			continue
+		}
+		entry := scores[imdbId]
+		entry.Movie = movie
+		entry.Score = math.Round(entry.Score*1000) / 1000
+		ranking = append(ranking, *entry)
+	}
+
+	trendingCache.Lock()
+	trendingCache.rankings[window] = ranking
+	trendingCache.refreshedAt[window] = now
+	trendingCache.Unlock()
+	return nil
+}
+
+// decayedActivity sums exp(-ln2 * age / halfLife) per movie over the events in a collection since a cutoff
+func decayedActivity(ctx context.Context, collection *mongo.Collection, timeField string, filter bson.M, since, now time.Time, halfLife time.Duration) ([]trendingActivity, error) {
+	match := bson.M{timeField: bson.M{"$gte": since}}
+	for key, value := range filter {
+		match[key] = value
+	}
+	decayRate := -math.Ln2 / float64(halfLife.Milliseconds())
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{
+			"_id": "$imdb_id",
+			"score": bson.M{"$sum": bson.M{"$exp": bson.M{"$multiply": bson.A{
+				decayRate,
+				bson.M{"$subtract": bson.A{now, "$" + timeField}},
+			}}}},
+			"events": bson.M{"$sum": 1},
+		}}},
+	}
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var activity []trendingActivity
+	if err = cursor.All(ctx, &activity); err != nil {
+		return nil, err
+	}
+	return activity, nil
+}
@@ -39,6 +39,12 @@ func RegisterUser() gin.HandlerFunc {
 
 		fmt.Printf("Registration attempt for email: %s\n", user.Email)
 
+		genres, ok := requireCatalogGenres(c, user.FavouriteGenres)
+		if !ok {
+			return
+		}
+		user.FavouriteGenres = genres
+
 		hashedPassword, err := HashPassword(user.Password)
 
 		if err != nil {
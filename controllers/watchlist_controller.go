@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/database"
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/models"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var watchlistCollection *mongo.Collection = database.OpenCollection("Watchlist")
+
+// AddToWatchlist saves a movie for the authenticated user. Adding the same movie twice is a no-op.
+func AddToWatchlist() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		imdbId := c.Param("imdb_id")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		exists, err := playableTitleExists(ctx, imdbId, true)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to look up movie"})
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+			return
+		}
+
+		filter := bson.M{"user_id": c.GetString("userId"), "imdb_id": imdbId}
+		update := bson.M{"$setOnInsert": bson.M{"added_at": time.Now()}}
+		result, err := watchlistCollection.UpdateOne(ctx, filter, update, options.UpdateOne().SetUpsert(true))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update watchlist"})
+			return
+		}
+
+		if result.UpsertedCount == 0 {
+			c.JSON(http.StatusOK, gin.H{"message": "Movie already in watchlist", "imdb_id": imdbId})
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"message": "Movie added to watchlist", "imdb_id": imdbId})
+	}
+}
+
+// RemoveFromWatchlist drops a movie from the authenticated user's watchlist
+func RemoveFromWatchlist() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		filter := bson.M{"user_id": c.GetString("userId"), "imdb_id": c.Param("imdb_id")}
+		result, err := watchlistCollection.DeleteOne(ctx, filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update watchlist"})
+			return
+		}
+		if result.DeletedCount == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not in watchlist"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Movie removed from watchlist"})
+	}
+}
+
+// GetWatchlist lists the authenticated user's watchlist with embedded movie summaries.
+// ?sort=added_at|ranking and ?order=asc|desc control the ordering.
+func GetWatchlist() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, limit, err := parsePagination(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		sortFields := map[string]string{
+			"added_at": "added_at",
+			"ranking":  "movie.ranking.ranking_value",
+		}
+		sortField, ok := sortFields[c.DefaultQuery("sort", "added_at")]
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "sort must be added_at or ranking"})
+			return
+		}
+		order := -1
+		if c.DefaultQuery("order", "desc") == "asc" {
+			order = 1
+		}
+
+		// Joining the public catalog also hides entries whose movie is unpublished, in the trash or gone
+		pipeline := mongo.Pipeline{
+			{{Key: "$match", Value: bson.M{"user_id": c.GetString("userId")}}},
+			{{Key: "$lookup", Value: bson.M{
+				"from":         "Movie",
+				"localField":   "imdb_id",
+				"foreignField": "imdb_id",
+				"pipeline":     bson.A{bson.M{"$match": publicMovieFilter(bson.M{})}},
+				"as":           "movie",
+			}}},
+			{{Key: "$unwind", Value: "$movie"}},
+			{{Key: "$facet", Value: bson.M{
+				"items": bson.A{
+					bson.M{"$sort": bson.D{{Key: sortField, Value: order}, {Key: "_id", Value: order}}},
+					bson.M{"$skip": (page - 1) * limit},
+					bson.M{"$limit": limit},
+				},
+				"total": bson.A{bson.M{"$count": "count"}},
+			}}},
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		cursor, err := watchlistCollection.Aggregate(ctx, pipeline)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		defer cursor.Close(ctx)
+
+		var results []struct {
+			Items []models.WatchlistEntry `bson:"items"`
+			Total []struct {
+				Count int64 `bson:"count"`
+			} `bson:"total"`
+		}
+		if err = cursor.All(ctx, &results); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		items := []models.WatchlistEntry{}
+		var total int64
+		if len(results) > 0 {
+			if results[0].Items != nil {
+				items = results[0].Items
+			}
+			if len(results[0].Total) > 0 {
+				total = results[0].Total[0].Count
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"watchlist":   items,
+			"page":        page,
+			"limit":       limit,
+			"total_found": total,
+		})
+	}
+}
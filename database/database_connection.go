@@ -6,6 +6,7 @@ import (
 	"log"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -95,11 +96,18 @@ func dbInstance() *mongo.Client {
 // Global client instance - stores POINTER to connection
 // Why pointer? Sharing same connection across app (no copying)
 // Node.js equivalent: mongoose handles this internally
-var Client *mongo.Client = dbInstance()
+// It is created by the first OpenCollection call, so packages that import database
+// without opening a collection (e.g. in unit tests) never need a running MongoDB.
+var Client *mongo.Client
+var connectOnce sync.Once
 
-// OpenCollection gets a specific collection
+// OpenCollection gets a specific collection, connecting on first use
 // Returns: *mongo.Collection (pointer) for same efficiency reasons
 func OpenCollection(collectionName string) *mongo.Collection {
+	connectOnce.Do(func() {
+		Client = dbInstance()
+	})
+
 	// Load .env file (only needed for local development)
 	err := godotenv.Load(".env")
 	if err != nil {
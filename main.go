@@ -1,10 +1,16 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/controllers"
 	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/routes"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -28,15 +34,31 @@ func main() {
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     allowedOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "If-Match"},
+		ExposeHeaders:    []string{"Content-Length", "ETag"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
 
+	controllers.EnsureIndexes()
+
 	// Apply routes
 	routes.MovieRoutes(router)
 	routes.UserRoutes(router)
+	routes.MediaRoutes(router)
+	routes.AdminRoutes(router)
+	routes.MeRoutes(router)
+	routes.SeriesRoutes(router)
+
+	// Background workers
+	controllers.StartThumbnailWorker()
+	controllers.StartProgressFlusher()
+	controllers.StartHistoryRetentionJob()
+	controllers.StartItemSimilarityJob()
+	controllers.StartTrendingAggregator()
+	controllers.StartMetadataRefreshJob()
+	controllers.StartPublishScheduler()
+	controllers.StartTrashPurgeJob()
 
 	// Get port from environment or default to 8080
 	port := os.Getenv("PORT")
@@ -44,7 +66,27 @@ func main() {
 		port = "8080"
 	}
 
-	if err := router.Run(":" + port); err != nil {
-		fmt.Println("Failed to start server", err)
+	server := &http.Server{Addr: ":" + port, Handler: router}
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+
+	// On SIGTERM, finish in-flight requests and flush buffered progress before exiting
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	select {
+	case err := <-serverErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			fmt.Println("Failed to start server", err)
+		}
+	case <-quit:
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := server.Shutdown(ctx); err != nil {
+			fmt.Println("Failed to shut down server", err)
+		}
+		cancel()
 	}
+
+	controllers.FlushProgress()
 }
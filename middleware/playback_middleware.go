@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// PlaybackMiddleWare authorizes media requests from the signed query string instead of the
+// Bearer header, so native players that cannot set headers can still stream.
+func PlaybackMiddleWare() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		imdbId := c.Param("imdb_id")
+
+		userId, err := utils.VerifyPlayback(c.Request.URL.Query(), imdbId)
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
+
+		c.Set("userId", userId)
+		c.Next()
+	}
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// GenreDefinition is a genre in the managed catalog. Movies, series and users embed a Genre
+// whose genre_id points here; its genre_name is kept equal to Name. Aliases are alternative
+// spellings ("Sci-Fi", "SciFi") that resolve to this genre, and ParentID makes it a sub-genre.
+type GenreDefinition struct {
+	ID        bson.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	GenreID   int           `bson:"genre_id" json:"genre_id" validate:"required,min=1"`
+	Name      string        `bson:"name" json:"name" validate:"required,min=2,max=100"`
+	Aliases   []string      `bson:"aliases,omitempty" json:"aliases,omitempty" validate:"omitempty,dive,min=2,max=100"`
+	ParentID  *int          `bson:"parent_id,omitempty" json:"parent_id,omitempty"`
+	CreatedAt time.Time     `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time     `bson:"updated_at" json:"updated_at"`
+}
+
+// GenreMigrationResult - summary of normalizing embedded genres against the catalog
+type GenreMigrationResult struct {
+	DryRun        bool     `json:"dry_run"`
+	MoviesUpdated int      `json:"movies_updated"`
+	SeriesUpdated int      `json:"series_updated"`
+	UsersUpdated  int      `json:"users_updated"`
+	Unresolved    []string `json:"unresolved"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// WatchHistoryEntry is one viewing session: consecutive progress reports for the same title on
+// the same device are folded into a single entry until the viewer pauses for longer than the session gap
+type WatchHistoryEntry struct {
+	ID             bson.ObjectID   `bson:"_id,omitempty" json:"_id,omitempty"`
+	UserID         string          `bson:"user_id" json:"user_id"`
+	ImdbID         string          `bson:"imdb_id" json:"imdb_id"`
+	Device         string          `bson:"device" json:"device"`
+	StartedAt      time.Time       `bson:"started_at" json:"started_at"`
+	LastWatchedAt  time.Time       `bson:"last_watched_at" json:"last_watched_at"`
+	WatchedSeconds float64         `bson:"watched_seconds" json:"watched_seconds"`
+	LastPosition   float64         `bson:"last_position" json:"last_position"`
+	Movie          *Movie          `bson:"-" json:"movie,omitempty"`
+	Episode        *WatchedEpisode `bson:"-" json:"episode,omitempty"`
+}
@@ -0,0 +1,21 @@
+package models
+
+// ImportRowError - why one row of a bulk import was rejected. Row is the line number in the file.
+type ImportRowError struct {
+	Row    int    `json:"row"`
+	ImdbID string `json:"imdb_id,omitempty"`
+	Error  string `json:"error"`
+}
+
+// ImportSummary - outcome of a bulk catalog import
+type ImportSummary struct {
+	Format          string           `json:"format"`
+	Mode            string           `json:"mode"`
+	DryRun          bool             `json:"dry_run"`
+	TotalRows       int              `json:"total_rows"`
+	Inserted        int              `json:"inserted"`
+	Updated         int              `json:"updated"`
+	Failed          int              `json:"failed"`
+	Errors          []ImportRowError `json:"errors"`
+	ErrorsTruncated bool             `json:"errors_truncated,omitempty"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// MediaAsset tracks the packaged HLS output of a title
+type MediaAsset struct {
+	ID                  bson.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	ImdbID              string        `bson:"imdb_id" json:"imdb_id"`
+	SourcePath          string        `bson:"source_path" json:"source_path"`
+	Status              string        `bson:"status" json:"status"` // packaging, ready, failed
+	Error               string        `bson:"error,omitempty" json:"error,omitempty"`
+	Encrypted           bool          `bson:"encrypted" json:"encrypted"`
+	KeyRotationSegments int           `bson:"key_rotation_segments" json:"key_rotation_segments"`
+	SegmentCount        int           `bson:"segment_count" json:"segment_count"`
+	ThumbnailStatus     string        `bson:"thumbnail_status,omitempty" json:"thumbnail_status,omitempty"` // queued, generating, ready, failed
+	ThumbnailError      string        `bson:"thumbnail_error,omitempty" json:"thumbnail_error,omitempty"`
+	UpdatedAt           time.Time     `bson:"updated_at" json:"updated_at"`
+}
+
+// ContentKey is an AES-128 key protecting a run of HLS segments. Keys never leave the server
+// except through the key endpoint, so the raw bytes are hidden from JSON.
+type ContentKey struct {
+	ID        bson.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	ImdbID    string        `bson:"imdb_id" json:"imdb_id"`
+	KeyIndex  int           `bson:"key_index" json:"key_index"`
+	Key       []byte        `bson:"key" json:"-"`
+	CreatedAt time.Time     `bson:"created_at" json:"created_at"`
+}
+
+// PackageRequest - input for packaging a title's source video into HLS
+type PackageRequest struct {
+	SourcePath          string `json:"source_path" validate:"required"`
+	Encrypt             *bool  `json:"encrypt"`
+	KeyRotationSegments *int   `json:"key_rotation_segments" validate:"omitempty,min=0"`
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
@@ -37,6 +39,14 @@ type Genre struct {
 type Ranking struct {
 	RankingValue int    `bson:"ranking_value" json:"ranking_value" validate:"required"`
 	RankingName  string `bson:"ranking_name" json:"ranking_name" validate:"required"`
+	// Which review analyzer suggested RankingName ("openai" or "local")
+	RankingSource string `bson:"ranking_source,omitempty" json:"ranking_source,omitempty"`
+}
+
+// UserRatingSummary aggregates the community's ratings of a movie
+type UserRatingSummary struct {
+	Average float64 `bson:"average" json:"average"`
+	Count   int     `bson:"count" json:"count"`
 }
 
 type Movie struct {
@@ -48,4 +58,65 @@ type Movie struct {
 	Genre       []Genre       `bson:"genre" json:"genre" validate:"required,dive"`
 	AdminReview *string       `bson:"admin_review,omitempty" json:"admin_review,omitempty"`
 	Ranking     *Ranking      `bson:"ranking,omitempty" json:"ranking,omitempty"`
+	// Free-form descriptive tags used to find similar movies
+	Keywords []string `bson:"keywords,omitempty" json:"keywords,omitempty" validate:"omitempty,dive,min=1,max=100"`
+	// Average of user ratings, maintained whenever a user review changes
+	UserRating *UserRatingSummary `bson:"user_rating,omitempty" json:"user_rating,omitempty"`
+	// Resized copies of an uploaded poster keyed by variant name (thumbnail, card, hero)
+	PosterVariants map[string]string `bson:"poster_variants,omitempty" json:"poster_variants,omitempty"`
+	// Details filled in from the external metadata provider, or by hand
+	Year              int        `bson:"year,omitempty" json:"year,omitempty" validate:"omitempty,min=1870,max=2100"`
+	Plot              string     `bson:"plot,omitempty" json:"plot,omitempty" validate:"max=10000"`
+	RuntimeMinutes    int        `bson:"runtime_minutes,omitempty" json:"runtime_minutes,omitempty" validate:"min=0"`
+	MetadataUpdatedAt *time.Time `bson:"metadata_updated_at,omitempty" json:"metadata_updated_at,omitempty"`
+	// Publication workflow. Only published movies are public; movies created before the
+	// workflow existed have no status and count as published.
+	Status      string     `bson:"status,omitempty" json:"status,omitempty" validate:"omitempty,oneof=draft in_review published unpublished"`
+	PublishAt   *time.Time `bson:"publish_at,omitempty" json:"publish_at,omitempty"`
+	PublishedAt *time.Time `bson:"published_at,omitempty" json:"published_at,omitempty"`
+	// Soft delete. Deleted movies sit in the trash until restored or purged.
+	DeletedAt *time.Time `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	DeletedBy string     `bson:"deleted_by,omitempty" json:"deleted_by,omitempty"`
+	// Incremented by every editorial change (not by user ratings); served as the ETag and
+	// checked against If-Match on admin edits
+	Version int `bson:"version" json:"version"`
+}
+
+// MovieSummary - the subset of a movie embedded in lists such as the watchlist
+type MovieSummary struct {
+	ImdbID     string   `bson:"imdb_id" json:"imdb_id"`
+	Title      string   `bson:"title" json:"title"`
+	PosterPath string   `bson:"poster_path" json:"poster_path"`
+	Genre      []Genre  `bson:"genre" json:"genre"`
+	Ranking    *Ranking `bson:"ranking,omitempty" json:"ranking,omitempty"`
+}
+
+// ScoredMovie - a movie with a relevance score, used by similarity and recommendation results
+type ScoredMovie struct {
+	Movie Movie   `json:"movie"`
+	Score float64 `json:"score"`
+}
+
+// TrendingMovie - a movie ranked by recent, time-decayed audience activity
+type TrendingMovie struct {
+	Movie         Movie   `json:"movie"`
+	Score         float64 `json:"score"`
+	Views         int     `json:"views"`
+	WatchlistAdds int     `json:"watchlist_adds"`
+	Ratings       int     `json:"ratings"`
+}
+
+// MetadataImportRequest - options for importing a movie's metadata from the external provider
+type MetadataImportRequest struct {
+	// Required when the movie does not exist yet, since every movie needs a trailer
+	YouTubeID string `json:"youtube_id"`
+	// Also link cast and crew, creating people that are not in the directory yet
+	ImportCredits bool `json:"import_credits"`
+}
+
+// PublicationUpdate - input for moving a movie through the publication workflow. PublishAt
+// schedules a draft or in-review movie to be published automatically; null clears the schedule.
+type PublicationUpdate struct {
+	Status    string     `json:"status" validate:"required,oneof=draft in_review published unpublished"`
+	PublishAt *time.Time `json:"publish_at"`
 }
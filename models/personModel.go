@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Person is someone who worked on movies, as cast or crew
+type Person struct {
+	ID          bson.ObjectID     `bson:"_id,omitempty" json:"_id,omitempty"`
+	Name        string            `bson:"name" json:"name" validate:"required,min=1,max=200"`
+	Bio         string            `bson:"bio,omitempty" json:"bio,omitempty" validate:"max=10000"`
+	PhotoURL    string            `bson:"photo_url,omitempty" json:"photo_url,omitempty" validate:"omitempty,url"`
+	ExternalIDs map[string]string `bson:"external_ids,omitempty" json:"external_ids,omitempty"` // e.g. {"imdb": "nm0000138"}
+	CreatedAt   time.Time         `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time         `bson:"updated_at" json:"updated_at"`
+}
+
+// Credit links a person to a movie in one role. Character is only set for actors;
+// Order is the billing position used to sort the cast.
+type Credit struct {
+	ID        bson.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	PersonID  bson.ObjectID `bson:"person_id" json:"person_id"`
+	ImdbID    string        `bson:"imdb_id" json:"imdb_id"`
+	Role      string        `bson:"role" json:"role"`
+	Character string        `bson:"character,omitempty" json:"character,omitempty"`
+	Order     int           `bson:"order" json:"order"`
+	CreatedAt time.Time     `bson:"created_at" json:"created_at"`
+	Person    *Person       `bson:"person,omitempty" json:"person,omitempty"`
+}
+
+// CreditInput - input for adding or editing a credit
+type CreditInput struct {
+	PersonID  string `json:"person_id" validate:"required"`
+	Role      string `json:"role" validate:"required,oneof=actor director writer"`
+	Character string `json:"character" validate:"max=200"`
+	Order     int    `json:"order" validate:"min=0"`
+}
+
+// FilmographyEntry - one credit of a person with the movie it belongs to
+type FilmographyEntry struct {
+	Movie     MovieSummary `json:"movie"`
+	Role      string       `json:"role"`
+	Character string       `json:"character,omitempty"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// WatchProgress is the resume position of one user in one title
+type WatchProgress struct {
+	ID              bson.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	UserID          string        `bson:"user_id" json:"user_id"`
+	ImdbID          string        `bson:"imdb_id" json:"imdb_id"`
+	PositionSeconds float64       `bson:"position_seconds" json:"position_seconds"`
+	DurationSeconds float64       `bson:"duration_seconds" json:"duration_seconds"`
+	Device          string        `bson:"device" json:"device"`
+	Finished        bool          `bson:"finished" json:"finished"`
+	UpdatedAt       time.Time     `bson:"updated_at" json:"updated_at"`
+}
+
+// ProgressUpdate - input sent by players while a title is playing
+type ProgressUpdate struct {
+	PositionSeconds *float64 `json:"position_seconds" validate:"required,min=0"`
+	DurationSeconds float64  `json:"duration_seconds" validate:"required,gt=0"`
+	Device          string   `json:"device" validate:"max=100"`
+}
+
+// ContinueWatchingItem - a partially watched title with its resume position.
+// Exactly one of Movie and Episode is set.
+type ContinueWatchingItem struct {
+	Movie           *Movie          `json:"movie,omitempty"`
+	Episode         *WatchedEpisode `json:"episode,omitempty"`
+	PositionSeconds float64         `json:"position_seconds"`
+	DurationSeconds float64         `json:"duration_seconds"`
+	ProgressPercent float64         `json:"progress_percent"`
+	Device          string          `json:"device"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// SimilarItem is a neighbouring movie in the item-item similarity model
+type SimilarItem struct {
+	ImdbID string  `bson:"imdb_id" json:"imdb_id"`
+	Score  float64 `bson:"score" json:"score"`
+}
+
+// ItemSimilarity stores the most similar movies of one movie, computed offline from
+// user ratings and watch history
+type ItemSimilarity struct {
+	ID        bson.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	ImdbID    string        `bson:"imdb_id" json:"imdb_id"`
+	Neighbors []SimilarItem `bson:"neighbors" json:"neighbors"`
+	UpdatedAt time.Time     `bson:"updated_at" json:"updated_at"`
+}
+
+// RecommendationReason explains why a movie was recommended. Type is "genre", "similar_to" or
+// "more_like_this"; Genre or ImdbID/Title identify what it was matched on.
+type RecommendationReason struct {
+	Type   string `json:"type"`
+	Genre  string `json:"genre,omitempty"`
+	ImdbID string `json:"imdb_id,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Text   string `json:"text"`
+}
+
+// RecommendedMovie - a recommended movie with the reason it was picked
+type RecommendedMovie struct {
+	Movie
+	Reason RecommendationReason `json:"reason"`
+}
+
+// RecommendationFeedback is a user's latest signal about a movie in their recommendations
+type RecommendationFeedback struct {
+	ID        bson.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	UserID    string        `bson:"user_id" json:"user_id"`
+	ImdbID    string        `bson:"imdb_id" json:"imdb_id"`
+	Signal    string        `bson:"signal" json:"signal"`
+	UpdatedAt time.Time     `bson:"updated_at" json:"updated_at"`
+}
+
+// RecommendationFeedbackInput - input for recommendation feedback
+type RecommendationFeedbackInput struct {
+	ImdbID string `json:"imdb_id" validate:"required"`
+	Signal string `json:"signal" validate:"required,oneof=not_interested more_like_this"`
+}
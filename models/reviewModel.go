@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// UserReview is a user's rating and optional written review of a movie. Each user has at most one per movie.
+type UserReview struct {
+	ID            bson.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	ImdbID        string        `bson:"imdb_id" json:"imdb_id"`
+	UserID        string        `bson:"user_id" json:"user_id"`
+	UserName      string        `bson:"user_name" json:"user_name"`
+	Rating        int           `bson:"rating" json:"rating"`
+	Text          string        `bson:"text,omitempty" json:"text,omitempty"`
+	HelpfulCount  int           `bson:"helpful_count" json:"helpful_count"`
+	HelpfulVoters []string      `bson:"helpful_voters,omitempty" json:"-"`
+	CreatedAt     time.Time     `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time     `bson:"updated_at" json:"updated_at"`
+
+	// Moderation state. Only approved reviews are public and count towards the movie's user rating.
+	Status         string         `bson:"status" json:"status" validate:"oneof=pending approved rejected"`
+	Flags          []string       `bson:"flags,omitempty" json:"flags,omitempty"`
+	ReportCount    int            `bson:"report_count" json:"report_count"`
+	Reports        []ReviewReport `bson:"reports,omitempty" json:"reports,omitempty"`
+	ModeratedBy    string         `bson:"moderated_by,omitempty" json:"moderated_by,omitempty"`
+	ModeratedAt    *time.Time     `bson:"moderated_at,omitempty" json:"moderated_at,omitempty"`
+	ModerationNote string         `bson:"moderation_note,omitempty" json:"moderation_note,omitempty"`
+}
+
+// ReviewReport is one user's complaint about a review
+type ReviewReport struct {
+	UserID    string    `bson:"user_id" json:"user_id"`
+	Reason    string    `bson:"reason" json:"reason"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
+
+// ReviewReportInput - input for reporting a review
+type ReviewReportInput struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+// ModerationDecision - input for a moderator's decision on a review
+type ModerationDecision struct {
+	Decision string `json:"decision" validate:"required,oneof=approve reject"`
+	Note     string `json:"note" validate:"max=1000"`
+}
+
+// ReviewInput - input for creating or editing a user review
+type ReviewInput struct {
+	Rating int    `json:"rating" validate:"required,min=1,max=10"`
+	Text   string `json:"text" validate:"max=5000"`
+}
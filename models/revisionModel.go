@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// FieldChange is one top-level movie field that a revision changed. A nil Before or After
+// means the field was absent on that side of the change.
+type FieldChange struct {
+	Field  string `bson:"field" json:"field"`
+	Before any    `bson:"before" json:"before,omitempty"`
+	After  any    `bson:"after" json:"after,omitempty"`
+}
+
+// MovieRevision records who changed a movie, when, through which action and what changed.
+// A revision is numbered with the movie version the change produced, so numbers are unique per
+// movie but may skip versions that changed nothing tracked.
+type MovieRevision struct {
+	ID        bson.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	ImdbID    string        `bson:"imdb_id" json:"imdb_id"`
+	Revision  int           `bson:"revision" json:"revision"`
+	ChangedBy string        `bson:"changed_by" json:"changed_by"`
+	ChangedAt time.Time     `bson:"changed_at" json:"changed_at"`
+	// What made the change: create, import, admin_review, publication, scheduler, poster, metadata,
+	// delete, restore (of a revision) or restore_trash
+	Source string `bson:"source" json:"source"`
+	// Set on restore revisions: the revision whose state was brought back
+	RestoredFrom int           `bson:"restored_from,omitempty" json:"restored_from,omitempty"`
+	Changes      []FieldChange `bson:"changes" json:"changes"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Series is a show made of numbered seasons. Episodes are stored separately and
+// reference the series by SeriesID.
+type Series struct {
+	ID         bson.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	SeriesID   string        `bson:"series_id" json:"series_id" validate:"required"`
+	Title      string        `bson:"title" json:"title" validate:"required,min=2,max=500"`
+	Overview   string        `bson:"overview,omitempty" json:"overview,omitempty" validate:"max=5000"`
+	PosterPath string        `bson:"poster_path,omitempty" json:"poster_path,omitempty" validate:"omitempty,url"`
+	Genre      []Genre       `bson:"genre" json:"genre" validate:"required,dive"`
+	Seasons    []Season      `bson:"seasons" json:"seasons" validate:"dive"`
+	CreatedAt  time.Time     `bson:"created_at" json:"created_at"`
+}
+
+// Season - one season of a series
+type Season struct {
+	Number   int        `bson:"number" json:"number" validate:"required,min=1"`
+	Title    string     `bson:"title,omitempty" json:"title,omitempty" validate:"max=500"`
+	Overview string     `bson:"overview,omitempty" json:"overview,omitempty" validate:"max=5000"`
+	AirDate  *time.Time `bson:"air_date,omitempty" json:"air_date,omitempty"`
+}
+
+// Episode is one playable episode. ImdbID is the episode's own ID and keys its media,
+// subtitles, progress and history the same way a movie's IMDb ID does.
+type Episode struct {
+	ID             bson.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	SeriesID       string        `bson:"series_id" json:"series_id"`
+	SeasonNumber   int           `bson:"season_number" json:"season_number" validate:"required,min=1"`
+	EpisodeNumber  int           `bson:"episode_number" json:"episode_number" validate:"required,min=1"`
+	ImdbID         string        `bson:"imdb_id" json:"imdb_id" validate:"required"`
+	Title          string        `bson:"title" json:"title" validate:"required,min=1,max=500"`
+	Overview       string        `bson:"overview,omitempty" json:"overview,omitempty" validate:"max=5000"`
+	RuntimeMinutes int           `bson:"runtime_minutes,omitempty" json:"runtime_minutes,omitempty" validate:"min=0"`
+	AirDate        *time.Time    `bson:"air_date,omitempty" json:"air_date,omitempty"`
+	CreatedAt      time.Time     `bson:"created_at" json:"created_at"`
+}
+
+// WatchedEpisode is an episode with the series and season it belongs to, as shown in
+// continue-watching and history. Series is returned without its seasons.
+type WatchedEpisode struct {
+	Episode Episode `json:"episode"`
+	Series  Series  `json:"series"`
+	Season  *Season `json:"season,omitempty"`
+}
+
+// NextEpisode tells a player what to play next in a series. Status is "start" (nothing watched yet),
+// "resume" (the last episode was left unfinished), "next" (the following episode) or "completed".
+type NextEpisode struct {
+	SeriesID        string   `json:"series_id"`
+	Status          string   `json:"status"`
+	Episode         *Episode `json:"episode,omitempty"`
+	PositionSeconds float64  `json:"position_seconds"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Subtitle describes a WebVTT caption track of a title. The track itself is stored next to the
+// packaged media as subtitles/<language>.vtt with a matching subtitles/<language>.m3u8.
+type Subtitle struct {
+	ID              bson.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	ImdbID          string        `bson:"imdb_id" json:"imdb_id"`
+	Language        string        `bson:"language" json:"language"`
+	Label           string        `bson:"label" json:"label"`
+	SourceFormat    string        `bson:"source_format" json:"source_format"` // srt or vtt as uploaded
+	CueCount        int           `bson:"cue_count" json:"cue_count"`
+	DurationSeconds float64       `bson:"duration_seconds" json:"duration_seconds"`
+	Path            string        `bson:"path" json:"path"` // relative to the title's media root
+	CreatedAt       time.Time     `bson:"created_at" json:"created_at"`
+	UpdatedAt       time.Time     `bson:"updated_at" json:"updated_at"`
+}
+
+// SubtitleUpload - form fields sent alongside the subtitle file
+type SubtitleUpload struct {
+	Language string `form:"language" validate:"required,bcp47_language_tag"`
+	Label    string `form:"label" validate:"omitempty,max=100"`
+}
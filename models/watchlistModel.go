@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// WatchlistEntry is a movie a user saved for later
+type WatchlistEntry struct {
+	ID      bson.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	UserID  string        `bson:"user_id" json:"user_id"`
+	ImdbID  string        `bson:"imdb_id" json:"imdb_id"`
+	AddedAt time.Time     `bson:"added_at" json:"added_at"`
+	Movie   *MovieSummary `bson:"movie,omitempty" json:"movie,omitempty"`
+}
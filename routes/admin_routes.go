@@ -0,0 +1,47 @@
+package routes
+
+import (
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/controllers"
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+func AdminRoutes(router *gin.Engine) {
+	// Admin route group - requires a valid token with the ADMIN role
+	admin := router.Group("/admin")
+	admin.Use(middleware.AuthMiddleWare(), middleware.AdminOnly())
+	{
+		admin.GET("/movies", controllers.GetAdminMovies())
+		admin.GET("/movies/:imdb_id", controllers.GetAdminMovie())
+		admin.PUT("/movies/:imdb_id/status", controllers.UpdatePublication())
+		admin.GET("/movies/:imdb_id/revisions", controllers.GetMovieRevisions())
+		admin.POST("/movies/:imdb_id/revisions/:rev/restore", controllers.RestoreMovieRevision())
+		admin.GET("/trash", controllers.GetTrash())
+		admin.POST("/trash/:imdb_id/restore", controllers.RestoreFromTrash())
+		admin.POST("/movies/:imdb_id/package", controllers.PackageMovie())
+		admin.GET("/movies/:imdb_id/media", controllers.GetMediaAsset())
+		admin.POST("/movies/:imdb_id/subtitles", controllers.UploadSubtitle())
+		admin.DELETE("/movies/:imdb_id/subtitles/:language", controllers.DeleteSubtitle())
+		admin.POST("/movies/:imdb_id/poster", controllers.UploadPoster())
+		admin.POST("/movies/:imdb_id/thumbnails", controllers.GenerateThumbnails())
+		admin.POST("/movies/:imdb_id/credits", controllers.AddCredit())
+		admin.PUT("/credits/:id", controllers.UpdateCredit())
+		admin.DELETE("/credits/:id", controllers.DeleteCredit())
+		admin.POST("/import", controllers.ImportMovies())
+		admin.GET("/export", controllers.ExportMovies())
+		admin.GET("/metadata/:imdb_id", controllers.PreviewMetadata())
+		admin.POST("/metadata/:imdb_id/import", controllers.ImportMetadata())
+		admin.POST("/genres", controllers.CreateGenre())
+		admin.PUT("/genres/:id", controllers.UpdateGenre())
+		admin.DELETE("/genres/:id", controllers.DeleteGenre())
+		admin.POST("/genres/migrate", controllers.MigrateGenres())
+		admin.POST("/people", controllers.CreatePerson())
+		admin.PUT("/people/:id", controllers.UpdatePerson())
+		admin.DELETE("/people/:id", controllers.DeletePerson())
+		admin.POST("/series", controllers.CreateSeries())
+		admin.POST("/series/:id/seasons", controllers.AddSeason())
+		admin.POST("/series/:id/episodes", controllers.AddEpisode())
+		admin.GET("/moderation", controllers.GetModerationQueue())
+		admin.POST("/moderation/:id/decision", controllers.DecideModeration())
+	}
+}
@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/controllers"
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+func MeRoutes(router *gin.Engine) {
+	// Routes scoped to the authenticated user
+	me := router.Group("/me")
+	me.Use(middleware.AuthMiddleWare())
+	{
+		me.PUT("/progress/:imdb_id", controllers.UpdateProgress())
+		me.GET("/progress/:imdb_id", controllers.GetProgress())
+		me.GET("/continue-watching", controllers.GetContinueWatching())
+		me.GET("/history", controllers.GetHistory())
+		me.DELETE("/history", controllers.ClearHistory())
+		me.DELETE("/history/:id", controllers.DeleteHistoryEntry())
+		me.GET("/watchlist", controllers.GetWatchlist())
+		me.POST("/watchlist/:imdb_id", controllers.AddToWatchlist())
+		me.DELETE("/watchlist/:imdb_id", controllers.RemoveFromWatchlist())
+		me.GET("/series/:id/next-episode", controllers.GetNextEpisode())
+		me.POST("/recommendations/feedback", controllers.SubmitRecommendationFeedback())
+	}
+}
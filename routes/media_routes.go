@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/controllers"
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+func MediaRoutes(router *gin.Engine) {
+	// Media routes are authorized by the signed playback URL rather than the Bearer header
+	media := router.Group("/media/:imdb_id")
+	media.Use(middleware.PlaybackMiddleWare())
+	{
+		media.GET("/*filepath", controllers.ServeMedia())
+	}
+
+	// Posters are public and cached forever; their URLs change whenever the image does
+	router.GET("/posters/:imdb_id/:hash/:variant", controllers.GetPoster())
+
+	// Key server referenced by #EXT-X-KEY in encrypted playlists
+	keys := router.Group("/keys/:imdb_id")
+	keys.Use(middleware.PlaybackMiddleWare())
+	{
+		keys.GET("/:key_index", controllers.GetContentKey())
+	}
+}
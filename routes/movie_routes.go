@@ -16,11 +16,24 @@ func MovieRoutes(router *gin.Engine) {
 			"endpoints": []string{
 				"GET /movies - Get all movies",
 				"GET /movies/top-rated - Get highest rated movies",
+				"GET /movies/trending - Get trending movies (window=24h|7d|30d)",
 				"GET /movies/genre/:genre - Get movies by genre",
+				"GET /genres - List the genre catalog",
 				"GET /movie/:imdb_id - Get specific movie",
-				"GET /movies/recommended/:user_id - Get personalized recommendations",
+				"GET /movies/:imdb_id/subtitles - List subtitle tracks",
+				"GET /movies/:imdb_id/reviews - List user reviews",
+				"GET /movies/:imdb_id/similar - Get \"more like this\" movies",
+				"GET /movies/:imdb_id/credits - Get cast and crew",
+				"GET /people/:id - Get a person with their filmography",
+				"GET /series/:id - Get a series with its seasons",
+				"GET /series/:id/seasons/:n/episodes - List the episodes of a season",
+				"GET /episodes/:imdb_id/next - Get the episode that follows an episode",
+				"GET /movies/recommended - Get your personalized recommendations (auth required)",
 				"POST /movies - Create new movie (auth required)",
 				"PUT /movies/:imdb_id/review - Add admin review (auth required)",
+				"POST /movies/:imdb_id/playback - Get a signed playback URL (auth required)",
+				"DELETE /movies/:imdb_id - Move a movie to the trash (admin only)",
+				"PUT /movies/:imdb_id/reviews - Rate and review a movie (auth required)",
 			},
 		})
 	})
@@ -28,18 +41,31 @@ func MovieRoutes(router *gin.Engine) {
 	// Public routes (no authentication needed)
 	router.GET("/movies", controllers.GetMovies())
 	router.GET("/movies/top-rated", controllers.GetTopRatedMovies())
+	router.GET("/movies/trending", controllers.GetTrendingMovies())
 	router.GET("/movies/genre/:genre", controllers.GetMoviesByGenre())
+	router.GET("/genres", controllers.GetGenres())
 	router.GET("/movie/:imdb_id", controllers.GetMovie())
-	router.GET("/movies/recommended/:user_id", controllers.GetRecommendedMovies())
+	router.GET("/movies/:imdb_id/subtitles", controllers.GetSubtitles())
+	router.GET("/movies/:imdb_id/reviews", controllers.GetMovieReviews())
+	router.GET("/movies/:imdb_id/similar", controllers.GetSimilarMovies())
+	router.GET("/movies/:imdb_id/credits", controllers.GetMovieCredits())
+	router.GET("/people/:id", controllers.GetPerson())
+	router.GET("/movies/recommended/:user_id", controllers.RecommendedMoviesMoved())
 
 	// Protected route group
 	protected := router.Group("/")
 	protected.Use(middleware.AuthMiddleWare())
 	{
+		protected.GET("/movies/recommended", controllers.GetRecommendedMovies())
 		protected.POST("/movies", controllers.MakeMovies())
 		protected.PUT("/movies/:imdb_id/review", controllers.AdminReviewUpdate())
+		protected.POST("/movies/:imdb_id/playback", controllers.CreatePlaybackURL())
+		protected.DELETE("/movies/:imdb_id", middleware.AdminOnly(), controllers.DeleteMovie())
+		protected.PUT("/movies/:imdb_id/reviews", controllers.UpsertReview())
+		protected.DELETE("/movies/:imdb_id/reviews", controllers.DeleteReview())
+		protected.POST("/reviews/:id/helpful", controllers.MarkReviewHelpful())
+		protected.POST("/reviews/:id/report", controllers.ReportReview())
 		// Add more protected routes here as needed
 		// protected.PUT("/movies/:id", controllers.UpdateMovie())
-		// protected.DELETE("/movies/:id", controllers.DeleteMovie())
 	}
 }
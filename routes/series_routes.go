@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"github.com/Futuredakster/GoProject/Server/MagicStreamMoviesServer/controllers"
+	"github.com/gin-gonic/gin"
+)
+
+func SeriesRoutes(router *gin.Engine) {
+	// Public catalog browsing for series
+	router.GET("/series/:id", controllers.GetSeries())
+	router.GET("/series/:id/seasons/:n/episodes", controllers.GetSeasonEpisodes())
+	router.GET("/episodes/:imdb_id/next", controllers.GetEpisodeAfter())
+}
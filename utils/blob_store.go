@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BlobStore is the storage backend for uploaded binary assets such as poster images.
+// Keys are slash separated paths like "posters/tt1234567/ab12cd34/card.jpg".
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Open(ctx context.Context, key string) (io.ReadSeekCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+var ErrBlobNotFound = errors.New("blob not found")
+
+// NewBlobStore picks the backend from BLOB_BACKEND. Only the local filesystem backend exists
+// today; object storage backends plug in here by implementing BlobStore.
+func NewBlobStore() BlobStore {
+	switch backend := GetEnvString("BLOB_BACKEND", "local"); backend {
+	case "local":
+		return &LocalBlobStore{Root: GetEnvString("BLOB_ROOT", "blobs")}
+	default:
+		log.Printf("Unknown BLOB_BACKEND %q, falling back to local storage", backend)
+		return &LocalBlobStore{Root: GetEnvString("BLOB_ROOT", "blobs")}
+	}
+}
+
+// LocalBlobStore keeps blobs as files under Root
+type LocalBlobStore struct {
+	Root string
+}
+
+func (s *LocalBlobStore) path(key string) (string, error) {
+	cleaned := filepath.Clean("/" + key)
+	if cleaned == "/" || strings.Contains(key, "..") {
+		return "", errors.New("invalid blob key")
+	}
+	return filepath.Join(s.Root, cleaned), nil
+}
+
+func (s *LocalBlobStore) Put(ctx context.Context, key string, data []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so readers never see a partial blob
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (s *LocalBlobStore) Open(ctx context.Context, key string) (io.ReadSeekCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrBlobNotFound
+	}
+	return file, err
+}
+
+func (s *LocalBlobStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"math"
+	"sort"
+)
+
+// ScoredItem is an item ID with a score
+type ScoredItem struct {
+	ID    string
+	Score float64
+}
+
+// BuildItemSimilarities computes item-item cosine similarity from user interactions
+// (user ID -> item ID -> interaction strength). For every item it keeps the top `neighbors`
+// most similar items that were interacted with by at least `minCommonUsers` of the same users.
+// maxItemsPerUser bounds the quadratic pair expansion for very active users.
+func BuildItemSimilarities(interactions map[string]map[string]float64, neighbors, minCommonUsers, maxItemsPerUser int) map[string][]ScoredItem {
+	norms := map[string]float64{}
+	dots := map[string]map[string]float64{}
+	common := map[string]map[string]int{}
+
+	for _, items := range interactions {
+		// Keep the strongest interactions when a user has too many
+		userItems := make([]ScoredItem, 0, len(items))
+		for id, weight := range items {
+			if weight > 0 {
+				userItems = append(userItems, ScoredItem{ID: id, Score: weight})
+			}
+		}
+		sort.Slice(userItems, func(i, j int) bool {
+			if userItems[i].Score != userItems[j].Score {
+				return userItems[i].Score > userItems[j].Score
+			}
+			return userItems[i].ID < userItems[j].ID
+		})
+		if maxItemsPerUser > 0 && len(userItems) > maxItemsPerUser {
+			userItems = userItems[:maxItemsPerUser]
+		}
+
+		for i, a := range userItems {
+			norms[a.ID] += a.Score * a.Score
+			for _, b := range userItems[i+1:] {
+				addPair(dots, common, a.ID, b.ID, a.Score*b.Score)
+				addPair(dots, common, b.ID, a.ID, a.Score*b.Score)
+			}
+		}
+	}
+
+	similarities := map[string][]ScoredItem{}
+	for item, row := range dots {
+		var scored []ScoredItem
+		for other, dot := range row {
+			if common[item][other] < minCommonUsers {
+				continue
+			}
+			scored = append(scored, ScoredItem{ID: other, Score: dot / math.Sqrt(norms[item]*norms[other])})
+		}
+		if len(scored) == 0 {
+			continue
+		}
+		sort.Slice(scored, func(i, j int) bool {
+			if scored[i].Score != scored[j].Score {
+				return scored[i].Score > scored[j].Score
+			}
+			return scored[i].ID < scored[j].ID
+		})
+		if len(scored) > neighbors {
+			scored = scored[:neighbors]
+		}
+		similarities[item] = scored
+	}
+	return similarities
+}
+
+func addPair(dots map[string]map[string]float64, common map[string]map[string]int, a, b string, product float64) {
+	if dots[a] == nil {
+		dots[a] = map[string]float64{}
+		common[a] = map[string]int{}
+	}
+	dots[a][b] += product
+	common[a][b]++
+}
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetEnvInt reads an integer environment variable, falling back when it is unset or invalid
+func GetEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// GetEnvDuration reads a Go duration string (e.g. "90s", "6h"), falling back when it is unset or invalid
+func GetEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// GetEnvString reads an environment variable, falling back when it is unset
+func GetEnvString(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// PublicBaseURL returns the externally visible origin of the API (no trailing slash).
+// PUBLIC_BASE_URL wins when set, otherwise the origin is derived from the request.
+func PublicBaseURL(c *gin.Context) string {
+	if base := os.Getenv("PUBLIC_BASE_URL"); base != "" {
+		return strings.TrimRight(base, "/")
+	}
+
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return scheme + "://" + c.Request.Host
+}
+
+// GetEnvBool reads a boolean environment variable ("true", "1", ...), falling back when it is unset or invalid
+func GetEnvBool(key string, fallback bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// GetEnvFloat reads a floating point environment variable, falling back when it is unset or invalid
+func GetEnvFloat(key string, fallback float64) float64 {
+	value, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,199 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var uriAttribute = regexp.MustCompile(`URI="([^"]*)"`)
+
+// appendQuery adds a signed query string to a relative playlist URI.
+// Absolute URIs point somewhere else and are left untouched.
+func appendQuery(uri, query string) string {
+	if uri == "" || strings.Contains(uri, "://") {
+		return uri
+	}
+	if strings.Contains(uri, "?") {
+		return uri + "&" + query
+	}
+	return uri + "?" + query
+}
+
+// SignPlaylist rewrites every relative URI in an HLS playlist (segments, variant playlists and
+// URI="..." tag attributes) so the player carries the playback signature on each follow-up request.
+func SignPlaylist(playlist []byte, query string) []byte {
+	var out bytes.Buffer
+	scanner := bufio.NewScanner(bytes.NewReader(playlist))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "#"):
+			line = uriAttribute.ReplaceAllStringFunc(line, func(attr string) string {
+				uri := uriAttribute.FindStringSubmatch(attr)[1]
+				return `URI="` + appendQuery(uri, query) + `"`
+			})
+		default:
+			line = appendQuery(line, query)
+		}
+		out.WriteString(line)
+		out.WriteByte('\n')
+	}
+	return out.Bytes()
+}
+
+// Segment is one media segment listed in an HLS media playlist
+type Segment struct {
+	URI      string
+	Duration float64
+}
+
+// MediaPlaylist is the subset of an HLS media playlist the packager needs
+type MediaPlaylist struct {
+	MediaSequence int64
+	Segments      []Segment
+}
+
+// ParseMediaPlaylist reads segment URIs and durations from an HLS media playlist
+func ParseMediaPlaylist(playlist []byte) MediaPlaylist {
+	var parsed MediaPlaylist
+	var duration float64
+
+	scanner := bufio.NewScanner(bytes.NewReader(playlist))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "#EXT-X-MEDIA-SEQUENCE:"):
+			parsed.MediaSequence, _ = strconv.ParseInt(strings.TrimPrefix(line, "#EXT-X-MEDIA-SEQUENCE:"), 10, 64)
+		case strings.HasPrefix(line, "#EXTINF:"):
+			value := strings.SplitN(strings.TrimPrefix(line, "#EXTINF:"), ",", 2)[0]
+			duration, _ = strconv.ParseFloat(value, 64)
+		case line == "" || strings.HasPrefix(line, "#"):
+		default:
+			parsed.Segments = append(parsed.Segments, Segment{URI: line, Duration: duration})
+			duration = 0
+		}
+	}
+	return parsed
+}
+
+// InsertKeyTags adds an AES-128 #EXT-X-KEY tag in front of every rotation-th segment.
+// A rotation of 0 uses a single key for the whole playlist. The IV attribute is omitted,
+// so players derive it from the media sequence number, matching EncryptSegment.
+func InsertKeyTags(playlist []byte, rotation int, keyURI func(keyIndex int) string) []byte {
+	var out bytes.Buffer
+	segment := 0
+	scanner := bufio.NewScanner(bytes.NewReader(playlist))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.HasPrefix(line, "#EXTINF:") {
+			if segment == 0 || (rotation > 0 && segment%rotation == 0) {
+				out.WriteString(`#EXT-X-KEY:METHOD=AES-128,URI="` + keyURI(KeyIndexForSegment(segment, rotation)) + `"` + "\n")
+			}
+			segment++
+		}
+		out.WriteString(line)
+		out.WriteByte('\n')
+	}
+	return out.Bytes()
+}
+
+// KeyIndexForSegment returns which content key protects the n-th segment (0-based)
+func KeyIndexForSegment(segment, rotation int) int {
+	if rotation <= 0 {
+		return 0
+	}
+	return segment / rotation
+}
+
+// EncryptSegment encrypts a segment with AES-128-CBC and PKCS#7 padding as required by
+// METHOD=AES-128, using the segment's media sequence number as the IV.
+func EncryptSegment(data, key []byte, sequence int64) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	iv := make([]byte, aes.BlockSize)
+	binary.BigEndian.PutUint64(iv[8:], uint64(sequence))
+
+	padding := aes.BlockSize - len(data)%aes.BlockSize
+	padded := make([]byte, len(data), len(data)+padding)
+	copy(padded, data)
+	padded = append(padded, bytes.Repeat([]byte{byte(padding)}, padding)...)
+
+	encrypted := make([]byte, len(padded))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, padded)
+	return encrypted, nil
+}
+
+// MasterPlaylist builds a single-variant master playlist pointing at a media playlist
+func MasterPlaylist(mediaPlaylistURI string, bandwidth int) []byte {
+	var out bytes.Buffer
+	out.WriteString("#EXTM3U\n")
+	out.WriteString("#EXT-X-VERSION:3\n")
+	out.WriteString("#EXT-X-STREAM-INF:BANDWIDTH=" + strconv.Itoa(bandwidth) + "\n")
+	out.WriteString(mediaPlaylistURI + "\n")
+	return out.Bytes()
+}
+
+// SubtitleRendition is a caption track advertised in a master playlist
+type SubtitleRendition struct {
+	Language string
+	Name     string
+	URI      string
+}
+
+const subtitleGroupID = "subs"
+
+// AddSubtitleRenditions advertises subtitle tracks in a master playlist with #EXT-X-MEDIA
+// TYPE=SUBTITLES entries and links every variant stream to the subtitle group
+func AddSubtitleRenditions(master []byte, renditions []SubtitleRendition) []byte {
+	if len(renditions) == 0 {
+		return master
+	}
+
+	var out bytes.Buffer
+	inserted := false
+	scanner := bufio.NewScanner(bytes.NewReader(master))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.HasPrefix(line, "#EXT-X-STREAM-INF:") {
+			if !inserted {
+				for _, rendition := range renditions {
+					out.WriteString(`#EXT-X-MEDIA:TYPE=SUBTITLES,GROUP-ID="` + subtitleGroupID + `",NAME="` + rendition.Name +
+						`",LANGUAGE="` + rendition.Language + `",DEFAULT=NO,AUTOSELECT=YES,URI="` + rendition.URI + `"` + "\n")
+				}
+				inserted = true
+			}
+			if !strings.Contains(line, "SUBTITLES=") {
+				line += `,SUBTITLES="` + subtitleGroupID + `"`
+			}
+		}
+		out.WriteString(line)
+		out.WriteByte('\n')
+	}
+	return out.Bytes()
+}
+
+// SignThumbnailTrack rewrites the image URIs of a WebVTT thumbnails track
+// ("sprite_000.jpg#xywh=...") so they carry the playback signature before the fragment
+func SignThumbnailTrack(track []byte, query string) []byte {
+	var out bytes.Buffer
+	scanner := bufio.NewScanner(bytes.NewReader(track))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if uri, fragment, found := strings.Cut(line, "#xywh="); found && !strings.Contains(line, "-->") {
+			line = appendQuery(uri, query) + "#xywh=" + fragment
+		}
+		out.WriteString(line)
+		out.WriteByte('\n')
+	}
+	return out.Bytes()
+}
@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+
+	// Register the decoders accepted for uploads
+	_ "image/gif"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// ImageVariant is a named target width for a resized image
+type ImageVariant struct {
+	Name  string
+	Width int
+}
+
+// PosterVariants are the sizes generated for every uploaded poster
+var PosterVariants = []ImageVariant{
+	{Name: "thumbnail", Width: 185},
+	{Name: "card", Width: 342},
+	{Name: "hero", Width: 1280},
+}
+
+// Uploads beyond this many pixels are rejected before decoding to avoid decompression bombs
+const maxImagePixels = 50_000_000
+
+// DecodeImage decodes a JPEG, PNG, GIF or WebP upload after checking its dimensions
+func DecodeImage(data []byte) (image.Image, string, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", errors.New("unsupported or corrupt image")
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxImagePixels {
+		return nil, "", errors.New("image dimensions are out of range")
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", errors.New("unsupported or corrupt image")
+	}
+	return img, format, nil
+}
+
+// ResizeToWidth scales an image to the given width keeping its aspect ratio.
+// Images are never upscaled. Transparent areas are flattened onto white,
+// since JPEG has no alpha channel and would otherwise render them black.
+func ResizeToWidth(img image.Image, width int) image.Image {
+	bounds := img.Bounds()
+	if bounds.Dx() <= width {
+		width = bounds.Dx()
+	}
+
+	height := bounds.Dy() * width / bounds.Dx()
+	if height < 1 {
+		height = 1
+	}
+	resized := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(resized, resized.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	if width == bounds.Dx() {
+		draw.Draw(resized, resized.Bounds(), img, bounds.Min, draw.Over)
+	} else {
+		draw.CatmullRom.Scale(resized, resized.Bounds(), img, bounds, draw.Over, nil)
+	}
+	return resized
+}
+
+// EncodeJPEG recompresses an image as a baseline JPEG
+func EncodeJPEG(img image.Image, quality int) ([]byte, error) {
+	var out bytes.Buffer
+	if err := jpeg.Encode(&out, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// TileSprite lays equally sized frames out left to right, top to bottom in a grid with the given number of columns
+func TileSprite(frames []image.Image, columns int) image.Image {
+	frameWidth := frames[0].Bounds().Dx()
+	frameHeight := frames[0].Bounds().Dy()
+	if len(frames) < columns {
+		columns = len(frames)
+	}
+	rows := (len(frames) + columns - 1) / columns
+
+	sprite := image.NewRGBA(image.Rect(0, 0, columns*frameWidth, rows*frameHeight))
+	for i, frame := range frames {
+		x := (i % columns) * frameWidth
+		y := (i / columns) * frameHeight
+		target := image.Rect(x, y, x+frameWidth, y+frameHeight)
+		draw.Draw(sprite, target, frame, frame.Bounds().Min, draw.Src)
+	}
+	return sprite
+}
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestResizeToWidthFlattensTransparency(t *testing.T) {
+	tests := []struct {
+		name      string
+		width     int
+		wantWidth int
+	}{
+		{"downscaled", 2, 2},
+		{"already narrow", 8, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := image.NewNRGBA(image.Rect(0, 0, 4, 4)) // fully transparent
+			got := ResizeToWidth(src, tt.width)
+			if got.Bounds().Dx() != tt.wantWidth {
+				t.Fatalf("width = %d, want %d", got.Bounds().Dx(), tt.wantWidth)
+			}
+			r, g, b, a := got.At(0, 0).RGBA()
+			wr, wg, wb, wa := color.White.RGBA()
+			if r != wr || g != wg || b != wb || a != wa {
+				t.Errorf("pixel = %v, want white", got.At(0, 0))
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrMetadataNotFound is returned when the provider has no record for an IMDb ID
+var ErrMetadataNotFound = errors.New("metadata not found")
+
+// MovieMetadata is what an external movie database knows about a title.
+// Missing values are left empty.
+type MovieMetadata struct {
+	ImdbID         string   `json:"imdb_id"`
+	Title          string   `json:"title"`
+	Year           int      `json:"year,omitempty"`
+	Plot           string   `json:"plot,omitempty"`
+	RuntimeMinutes int      `json:"runtime_minutes,omitempty"`
+	Genres         []string `json:"genres,omitempty"`
+	Directors      []string `json:"directors,omitempty"`
+	Writers        []string `json:"writers,omitempty"`
+	Actors         []string `json:"actors,omitempty"`
+	PosterURL      string   `json:"poster_url,omitempty"`
+}
+
+// MetadataProvider looks up movie metadata by IMDb ID
+type MetadataProvider interface {
+	Name() string
+	FetchMovie(ctx context.Context, imdbId string) (MovieMetadata, error)
+}
+
+// NewMetadataProvider returns the provider selected by METADATA_PROVIDER, or nil when
+// metadata enrichment is not configured
+func NewMetadataProvider() MetadataProvider {
+	switch GetEnvString("METADATA_PROVIDER", "omdb") {
+	case "omdb":
+		apiKey := GetEnvString("OMDB_API_KEY", "")
+		if apiKey == "" {
+			return nil
+		}
+		return &OMDbProvider{
+			BaseURL: GetEnvString("OMDB_BASE_URL", "https://www.omdbapi.com"),
+			APIKey:  apiKey,
+			Client:  &http.Client{Timeout: GetEnvDuration("METADATA_TIMEOUT", 10*time.Second)},
+		}
+	default:
+		return nil
+	}
+}
+
+// OMDbProvider reads the OMDb API (or anything answering the same ?i=<imdb_id> JSON format)
+type OMDbProvider struct {
+	BaseURL string
+	APIKey  string
+	Client  *http.Client
+}
+
+func (p *OMDbProvider) Name() string {
+	return "omdb"
+}
+
+type omdbResponse struct {
+	Response string `json:"Response"`
+	Error    string `json:"Error"`
+	ImdbID   string `json:"imdbID"`
+	Title    string `json:"Title"`
+	Year     string `json:"Year"`
+	Plot     string `json:"Plot"`
+	Runtime  string `json:"Runtime"`
+	Genre    string `json:"Genre"`
+	Director string `json:"Director"`
+	Writer   string `json:"Writer"`
+	Actors   string `json:"Actors"`
+	Poster   string `json:"Poster"`
+}
+
+func (p *OMDbProvider) FetchMovie(ctx context.Context, imdbId string) (MovieMetadata, error) {
+	query := url.Values{}
+	query.Set("i", imdbId)
+	query.Set("plot", "full")
+	query.Set("apikey", p.APIKey)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(p.BaseURL, "/")+"/?"+query.Encode(), nil)
+	if err != nil {
+		return MovieMetadata{}, err
+	}
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return MovieMetadata{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return MovieMetadata{}, ErrMetadataNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return MovieMetadata{}, fmt.Errorf("metadata provider returned %s", resp.Status)
+	}
+
+	var parsed omdbResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return MovieMetadata{}, fmt.Errorf("invalid provider response: %v", err)
+	}
+	if parsed.Response != "True" {
+		if strings.Contains(strings.ToLower(parsed.Error), "not found") || strings.Contains(strings.ToLower(parsed.Error), "incorrect imdb id") {
+			return MovieMetadata{}, ErrMetadataNotFound
+		}
+		return MovieMetadata{}, fmt.Errorf("metadata provider error: %s", parsed.Error)
+	}
+
+	return MovieMetadata{
+		ImdbID:         imdbId,
+		Title:          omdbValue(parsed.Title),
+		Year:           leadingNumber(omdbValue(parsed.Year)),
+		Plot:           omdbValue(parsed.Plot),
+		RuntimeMinutes: leadingNumber(omdbValue(parsed.Runtime)),
+		Genres:         SplitNameList(parsed.Genre),
+		Directors:      SplitNameList(parsed.Director),
+		Writers:        SplitNameList(parsed.Writer),
+		Actors:         SplitNameList(parsed.Actors),
+		PosterURL:      omdbValue(parsed.Poster),
+	}, nil
+}
+
+// omdbValue maps OMDb's "N/A" placeholder to an empty string
+func omdbValue(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "N/A" {
+		return ""
+	}
+	return value
+}
+
+var leadingDigits = regexp.MustCompile(`^\d+`)
+
+// leadingNumber parses "148 min" or "2008–2013" into 148 or 2008, and anything else into 0
+func leadingNumber(value string) int {
+	number, err := strconv.Atoi(leadingDigits.FindString(value))
+	if err != nil {
+		return 0
+	}
+	return number
+}
+
+var roleNote = regexp.MustCompile(`\s*\([^)]*\)`)
+
+// SplitNameList splits a comma-separated list such as "Jonathan Nolan (screenplay), Christopher Nolan (story)"
+// into unique names without the parenthesized notes
+func SplitNameList(value string) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, part := range strings.Split(omdbValue(value), ",") {
+		name := strings.TrimSpace(roleNote.ReplaceAllString(part, ""))
+		if name == "" || name == "N/A" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
+}
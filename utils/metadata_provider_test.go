@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// fakeOMDbServer answers every request with the given status and body
+func fakeOMDbServer(t *testing.T, status int, body string) *OMDbProvider {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("i") != "tt0468569" || query.Get("apikey") != "test-key" {
+			t.Errorf("unexpected query %q", r.URL.RawQuery)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return &OMDbProvider{BaseURL: server.URL, APIKey: "test-key", Client: server.Client()}
+}
+
+func TestOMDbProviderFetchMovie(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("full payload", func(t *testing.T) {
+		provider := fakeOMDbServer(t, http.StatusOK, `{
+			"Response": "True",
+			"imdbID": "tt0468569",
+			"Title": "The Dark Knight",
+			"Year": "2008–2013",
+			"Plot": "N/A",
+			"Runtime": "148 min",
+			"Genre": "Action, Crime, Drama",
+			"Director": "Christopher Nolan",
+			"Writer": "Jonathan Nolan (screenplay), Christopher Nolan (screenplay), Christopher Nolan (story)",
+			"Actors": "N/A",
+			"Poster": "https://example.com/poster.jpg"
+		}`)
+		got, err := provider.FetchMovie(ctx, "tt0468569")
+		if err != nil {
+			t.Fatalf("FetchMovie() error = %v", err)
+		}
+		want := MovieMetadata{
+			ImdbID:         "tt0468569",
+			Title:          "The Dark Knight",
+			Year:           2008,
+			RuntimeMinutes: 148,
+			Genres:         []string{"Action", "Crime", "Drama"},
+			Directors:      []string{"Christopher Nolan"},
+			Writers:        []string{"Jonathan Nolan", "Christopher Nolan"},
+			PosterURL:      "https://example.com/poster.jpg",
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("FetchMovie() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("incorrect IMDb ID", func(t *testing.T) {
+		provider := fakeOMDbServer(t, http.StatusOK, `{"Response": "False", "Error": "Incorrect IMDb ID."}`)
+		if _, err := provider.FetchMovie(ctx, "tt0468569"); !errors.Is(err, ErrMetadataNotFound) {
+			t.Errorf("FetchMovie() error = %v, want ErrMetadataNotFound", err)
+		}
+	})
+
+	t.Run("404 response", func(t *testing.T) {
+		provider := fakeOMDbServer(t, http.StatusNotFound, `{}`)
+		if _, err := provider.FetchMovie(ctx, "tt0468569"); !errors.Is(err, ErrMetadataNotFound) {
+			t.Errorf("FetchMovie() error = %v, want ErrMetadataNotFound", err)
+		}
+	})
+
+	t.Run("500 response", func(t *testing.T) {
+		provider := fakeOMDbServer(t, http.StatusInternalServerError, `{}`)
+		_, err := provider.FetchMovie(ctx, "tt0468569")
+		if err == nil || errors.Is(err, ErrMetadataNotFound) {
+			t.Errorf("FetchMovie() error = %v, want a provider error", err)
+		}
+	})
+
+	t.Run("malformed JSON", func(t *testing.T) {
+		provider := fakeOMDbServer(t, http.StatusOK, `{"Response": "True", "Title": `)
+		_, err := provider.FetchMovie(ctx, "tt0468569")
+		if err == nil || errors.Is(err, ErrMetadataNotFound) {
+			t.Errorf("FetchMovie() error = %v, want a decode error", err)
+		}
+	})
+}
+
+func TestSplitNameList(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"", nil},
+		{"N/A", nil},
+		{"Christopher Nolan", []string{"Christopher Nolan"}},
+		{"Christian Bale, Heath Ledger,  Aaron Eckhart ", []string{"Christian Bale", "Heath Ledger", "Aaron Eckhart"}},
+		{"Jonathan Nolan (screenplay), Christopher Nolan (story), Jonathan Nolan (characters)", []string{"Jonathan Nolan", "Christopher Nolan"}},
+		{"Bob Kane (characters), , N/A", []string{"Bob Kane"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := SplitNameList(tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitNameList(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ContentChecker inspects user-submitted text and returns the reasons it needs a moderator,
+// or nothing when the text looks fine. New checks plug in by implementing this interface.
+type ContentChecker interface {
+	Check(text string) []string
+}
+
+// ContentCheckers runs several checks and collects every reason
+type ContentCheckers []ContentChecker
+
+func (checkers ContentCheckers) Check(text string) []string {
+	var reasons []string
+	for _, checker := range checkers {
+		reasons = append(reasons, checker.Check(text)...)
+	}
+	return reasons
+}
+
+// BannedWordChecker flags text containing any banned word (whole words, case-insensitive)
+type BannedWordChecker struct {
+	Words []string
+}
+
+var wordPattern = regexp.MustCompile(`[\p{L}\p{N}']+`)
+
+func (checker BannedWordChecker) Check(text string) []string {
+	banned := map[string]bool{}
+	for _, word := range checker.Words {
+		banned[strings.ToLower(word)] = true
+	}
+
+	for _, word := range wordPattern.FindAllString(strings.ToLower(text), -1) {
+		if banned[word] {
+			return []string{"banned_word"}
+		}
+	}
+	return nil
+}
+
+// HeuristicChecker flags overly long text and text with too many links, a common spam signal
+type HeuristicChecker struct {
+	MaxLength int
+	MaxLinks  int
+}
+
+var linkPattern = regexp.MustCompile(`(?i)(https?://|www\.)\S+`)
+
+func (checker HeuristicChecker) Check(text string) []string {
+	var reasons []string
+	if checker.MaxLength > 0 && len([]rune(text)) > checker.MaxLength {
+		reasons = append(reasons, "too_long")
+	}
+	if links := len(linkPattern.FindAllString(text, -1)); links > checker.MaxLinks {
+		reasons = append(reasons, "too_many_links:"+strconv.Itoa(links))
+	}
+	return reasons
+}
+
+// NewContentChecker builds the default review checks from the environment
+func NewContentChecker() ContentChecker {
+	var words []string
+	for _, word := range strings.Split(GetEnvString("MODERATION_BANNED_WORDS", ""), ",") {
+		if word = strings.TrimSpace(word); word != "" {
+			words = append(words, word)
+		}
+	}
+
+	return ContentCheckers{
+		BannedWordChecker{Words: words},
+		HeuristicChecker{
+			MaxLength: GetEnvInt("MODERATION_MAX_LENGTH", 2000),
+			MaxLinks:  GetEnvInt("MODERATION_MAX_LINKS", 1),
+		},
+	}
+}
@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+var PLAYBACK_SIGNING_KEY string = getPlaybackSigningKey()
+
+func getPlaybackSigningKey() string {
+	if key := GetEnvString("PLAYBACK_SIGNING_KEY", ""); key != "" {
+		return key
+	}
+	// Fall back to the JWT secret so development setups work without extra config
+	return SECRET_KEY
+}
+
+// PlaybackURLTTL is how long a signed playback URL stays valid.
+// It has to cover a whole viewing session because every segment is fetched with the same signature.
+func PlaybackURLTTL() time.Duration {
+	return time.Duration(GetEnvInt("PLAYBACK_URL_TTL_MINUTES", 360)) * time.Minute
+}
+
+// playbackSignature signs the fields length-prefixed, so no two (user, title) pairs share a signed
+// string whatever characters the IDs contain
+func playbackSignature(userId, imdbId string, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(PLAYBACK_SIGNING_KEY))
+	for _, field := range []string{userId, imdbId, strconv.FormatInt(expires, 10)} {
+		mac.Write([]byte(strconv.Itoa(len(field)) + ":" + field))
+	}
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// SignPlayback returns the query string (uid, exp, sig) that grants userId access to imdbId's media until expires
+func SignPlayback(userId, imdbId string, expires time.Time) string {
+	exp := expires.Unix()
+	query := url.Values{}
+	query.Set("uid", userId)
+	query.Set("exp", strconv.FormatInt(exp, 10))
+	query.Set("sig", playbackSignature(userId, imdbId, exp))
+	return query.Encode()
+}
+
+// VerifyPlayback checks a signed playback query for imdbId and returns the user it was issued to
+func VerifyPlayback(query url.Values, imdbId string) (string, error) {
+	userId := query.Get("uid")
+	sig := query.Get("sig")
+	if userId == "" || sig == "" || query.Get("exp") == "" {
+		return "", errors.New("playback signature is required")
+	}
+
+	exp, err := strconv.ParseInt(query.Get("exp"), 10, 64)
+	if err != nil {
+		return "", errors.New("invalid playback expiry")
+	}
+
+	expected := playbackSignature(userId, imdbId, exp)
+	if !hmac.Equal([]byte(sig), []byte(expected)) {
+		return "", errors.New("invalid playback signature")
+	}
+
+	if time.Now().Unix() > exp {
+		return "", errors.New("playback URL has expired")
+	}
+
+	return userId, nil
+}
+
+// PlaybackQuery extracts the signed parameters from an already verified request so they can
+// be forwarded unchanged; the signature is not bound to a path, so it holds for every file of the title.
+func PlaybackQuery(query url.Values) string {
+	forwarded := url.Values{}
+	for _, key := range []string{"uid", "exp", "sig"} {
+		forwarded.Set(key, query.Get(key))
+	}
+	return forwarded.Encode()
+}
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestPlaybackSignatureSeparatesFields(t *testing.T) {
+	if playbackSignature("user|tt1", "tt2", 100) == playbackSignature("user", "tt1|tt2", 100) {
+		t.Error("different user and title pairs produced the same signature")
+	}
+}
+
+func TestVerifyPlayback(t *testing.T) {
+	query, err := url.ParseQuery(SignPlayback("user-1", "tt0468569", time.Now().Add(time.Hour)))
+	if err != nil {
+		t.Fatalf("ParseQuery() error = %v", err)
+	}
+
+	if userId, err := VerifyPlayback(query, "tt0468569"); err != nil || userId != "user-1" {
+		t.Errorf("VerifyPlayback() = %q, %v, want user-1", userId, err)
+	}
+	if _, err := VerifyPlayback(query, "tt0000001"); err == nil {
+		t.Error("VerifyPlayback() accepted a signature for another title")
+	}
+
+	expired, _ := url.ParseQuery(SignPlayback("user-1", "tt0468569", time.Now().Add(-time.Minute)))
+	if _, err := VerifyPlayback(expired, "tt0468569"); err == nil {
+		t.Error("VerifyPlayback() accepted an expired URL")
+	}
+}
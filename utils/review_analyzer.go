@@ -0,0 +1,238 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ReviewAnalysis is the input handed to a review analyzer
+type ReviewAnalysis struct {
+	Review string
+	Rating int // the admin's numeric rating, 1-10
+}
+
+// ReviewAnalyzer classifies a review into one of the given ranking words.
+// Rankings are ordered from best to worst.
+type ReviewAnalyzer interface {
+	Name() string
+	SuggestRanking(ctx context.Context, input ReviewAnalysis, rankings []string) (string, error)
+}
+
+const defaultPromptTemplate = "return a response using one of these words: {rankings}. The response should be a single word and should not contain any other text. The response should be based on the following review:"
+
+// NewReviewAnalyzer uses an OpenAI-compatible chat endpoint when an API key is configured and
+// always falls back to the deterministic local analyzer when the provider fails
+func NewReviewAnalyzer() ReviewAnalyzer {
+	local := LocalReviewAnalyzer{}
+	apiKey := os.Getenv("api_key")
+	if apiKey == "" {
+		return local
+	}
+
+	return FallbackReviewAnalyzer{
+		Primary: &OpenAIReviewAnalyzer{
+			BaseURL:        GetEnvString("OPENAI_BASE_URL", "https://api.openai.com/v1"),
+			APIKey:         apiKey,
+			Model:          GetEnvString("OPENAI_MODEL", "gpt-4o-mini"),
+			PromptTemplate: GetEnvString("BASE_PROMPT_TEMPLATE", defaultPromptTemplate),
+			Client:         &http.Client{},
+		},
+		Fallback: local,
+		Timeout:  GetEnvDuration("REVIEW_ANALYSIS_TIMEOUT", 8*time.Second),
+	}
+}
+
+// BuildReviewPrompt fills {rankings} in the template and appends the review
+func BuildReviewPrompt(template string, rankings []string, review string) string {
+	return strings.ReplaceAll(template, "{rankings}", strings.Join(rankings, ", ")) + " " + review
+}
+
+// ParseRankingAnswer validates that a model answer is exactly one of the ranking words,
+// tolerating case, surrounding whitespace, quotes and trailing punctuation
+func ParseRankingAnswer(answer string, rankings []string) (string, error) {
+	word := strings.TrimFunc(strings.TrimSpace(answer), func(r rune) bool {
+		return unicode.IsPunct(r) || unicode.IsSpace(r)
+	})
+	if word == "" || strings.ContainsFunc(word, unicode.IsSpace) {
+		return "", fmt.Errorf("expected a single word, got %q", answer)
+	}
+
+	for _, ranking := range rankings {
+		if strings.EqualFold(word, ranking) {
+			return ranking, nil
+		}
+	}
+	return "", fmt.Errorf("%q is not one of the rankings", word)
+}
+
+// OpenAIReviewAnalyzer asks an OpenAI-compatible /chat/completions endpoint for the ranking word
+type OpenAIReviewAnalyzer struct {
+	BaseURL        string
+	APIKey         string
+	Model          string
+	PromptTemplate string
+	Client         *http.Client
+}
+
+func (a *OpenAIReviewAnalyzer) Name() string {
+	return "openai"
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model       string        `json:"model"`
+	Messages    []chatMessage `json:"messages"`
+	Temperature float64       `json:"temperature"`
+	MaxTokens   int           `json:"max_tokens"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+func (a *OpenAIReviewAnalyzer) SuggestRanking(ctx context.Context, input ReviewAnalysis, rankings []string) (string, error) {
+	body, err := json.Marshal(chatRequest{
+		Model:       a.Model,
+		Messages:    []chatMessage{{Role: "user", Content: BuildReviewPrompt(a.PromptTemplate, rankings, input.Review)}},
+		Temperature: 0,
+		MaxTokens:   5,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(a.BaseURL, "/")+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+a.APIKey)
+
+	resp, err := a.Client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("review analysis provider returned %s", resp.Status)
+	}
+
+	var parsed chatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return "", fmt.Errorf("invalid provider response: %v", err)
+	}
+	if len(parsed.Choices) == 0 {
+		return "", errors.New("provider returned no choices")
+	}
+
+	return ParseRankingAnswer(parsed.Choices[0].Message.Content, rankings)
+}
+
+// LocalReviewAnalyzer maps the numeric rating onto the ranking scale and nudges it one step
+// up or down when the review text is clearly positive or negative. It never fails.
+type LocalReviewAnalyzer struct{}
+
+func (LocalReviewAnalyzer) Name() string {
+	return "local"
+}
+
+var positiveWords = map[string]bool{
+	"amazing": true, "awesome": true, "beautiful": true, "brilliant": true, "enjoyed": true,
+	"excellent": true, "fantastic": true, "great": true, "loved": true, "masterpiece": true,
+	"perfect": true, "stunning": true, "superb": true, "wonderful": true,
+}
+
+var negativeWords = map[string]bool{
+	"awful": true, "bad": true, "boring": true, "disappointing": true, "dull": true,
+	"hated": true, "horrible": true, "mess": true, "poor": true, "terrible": true,
+	"waste": true, "weak": true, "worst": true,
+}
+
+func (LocalReviewAnalyzer) SuggestRanking(ctx context.Context, input ReviewAnalysis, rankings []string) (string, error) {
+	if len(rankings) == 0 {
+		return "", errors.New("no rankings to choose from")
+	}
+
+	// Rating 10 maps to the best ranking and rating 1 to the worst
+	last := len(rankings) - 1
+	rating := min(max(input.Rating, 1), 10)
+	index := int(float64((10-rating)*last)/9 + 0.5)
+
+	positive, negative := 0, 0
+	for _, word := range strings.FieldsFunc(strings.ToLower(input.Review), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}) {
+		if positiveWords[word] {
+			positive++
+		}
+		if negativeWords[word] {
+			negative++
+		}
+	}
+	if total := positive + negative; total > 0 {
+		sentiment := float64(positive-negative) / float64(total)
+		if sentiment >= 0.5 {
+			index--
+		} else if sentiment <= -0.5 {
+			index++
+		}
+	}
+
+	return rankings[min(max(index, 0), last)], nil
+}
+
+// FallbackReviewAnalyzer tries Primary within Timeout and uses Fallback when it errors
+type FallbackReviewAnalyzer struct {
+	Primary  ReviewAnalyzer
+	Fallback ReviewAnalyzer
+	Timeout  time.Duration
+}
+
+func (a FallbackReviewAnalyzer) Name() string {
+	return a.Primary.Name()
+}
+
+// Analyze returns the suggested ranking together with the name of the analyzer that produced it
+func (a FallbackReviewAnalyzer) Analyze(ctx context.Context, input ReviewAnalysis, rankings []string) (string, string, error) {
+	primaryCtx, cancel := context.WithTimeout(ctx, a.Timeout)
+	defer cancel()
+
+	ranking, err := a.Primary.SuggestRanking(primaryCtx, input, rankings)
+	if err == nil {
+		return ranking, a.Primary.Name(), nil
+	}
+	log.Printf("Review analysis with %s failed, falling back to %s: %v", a.Primary.Name(), a.Fallback.Name(), err)
+
+	ranking, err = a.Fallback.SuggestRanking(ctx, input, rankings)
+	return ranking, a.Fallback.Name(), err
+}
+
+func (a FallbackReviewAnalyzer) SuggestRanking(ctx context.Context, input ReviewAnalysis, rankings []string) (string, error) {
+	ranking, _, err := a.Analyze(ctx, input, rankings)
+	return ranking, err
+}
+
+// AnalyzeReview runs any analyzer and reports which one produced the answer
+func AnalyzeReview(ctx context.Context, analyzer ReviewAnalyzer, input ReviewAnalysis, rankings []string) (string, string, error) {
+	if fallback, ok := analyzer.(FallbackReviewAnalyzer); ok {
+		return fallback.Analyze(ctx, input, rankings)
+	}
+	ranking, err := analyzer.SuggestRanking(ctx, input, rankings)
+	return ranking, analyzer.Name(), err
+}
@@ -0,0 +1,154 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+var testRankings = []string{"Excellent", "Good", "Okay", "Bad", "Terrible"}
+
+func TestBuildReviewPrompt(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		rankings []string
+		review   string
+		want     string
+	}{
+		{"fills rankings", "pick one of: {rankings}.", []string{"Good", "Bad"}, "Loved it", "pick one of: Good, Bad. Loved it"},
+		{"fills every placeholder", "{rankings} / {rankings}", []string{"A"}, "x", "A / A x"},
+		{"no placeholder", "rate this:", []string{"Good"}, "Fine", "rate this: Fine"},
+		{"no rankings", "pick: {rankings}", nil, "Meh", "pick:  Meh"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BuildReviewPrompt(tt.template, tt.rankings, tt.review); got != tt.want {
+				t.Errorf("BuildReviewPrompt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRankingAnswer(t *testing.T) {
+	tests := []struct {
+		answer  string
+		want    string
+		wantErr bool
+	}{
+		{"Good", "Good", false},
+		{"good", "Good", false},
+		{"  EXCELLENT\n", "Excellent", false},
+		{`"Bad".`, "Bad", false},
+		{"Terrible!", "Terrible", false},
+		{"", "", true},
+		{"...", "", true},
+		{"Very good", "", true},
+		{"Great", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.answer, func(t *testing.T) {
+			got, err := ParseRankingAnswer(tt.answer, testRankings)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRankingAnswer(%q) error = %v, wantErr %v", tt.answer, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseRankingAnswer(%q) = %q, want %q", tt.answer, got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeChatServer answers /chat/completions with the given status and message content
+func fakeChatServer(t *testing.T, status int, content string, delay time.Duration) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat/completions" {
+			t.Errorf("request path = %q, want /chat/completions", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer test-key" {
+			t.Errorf("Authorization = %q, want Bearer test-key", got)
+		}
+		var req chatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("invalid request body: %v", err)
+		}
+		if req.Model != "test-model" || len(req.Messages) != 1 {
+			t.Errorf("unexpected request %+v", req)
+		}
+
+		if delay > 0 {
+			select {
+			case <-time.After(delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		w.WriteHeader(status)
+		var resp chatResponse
+		resp.Choices = append(resp.Choices, struct {
+			Message chatMessage `json:"message"`
+		}{Message: chatMessage{Role: "assistant", Content: content}})
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestAnalyzer(server *httptest.Server) *OpenAIReviewAnalyzer {
+	return &OpenAIReviewAnalyzer{
+		BaseURL:        server.URL + "/",
+		APIKey:         "test-key",
+		Model:          "test-model",
+		PromptTemplate: defaultPromptTemplate,
+		Client:         server.Client(),
+	}
+}
+
+func TestOpenAIReviewAnalyzer(t *testing.T) {
+	input := ReviewAnalysis{Review: "A solid film", Rating: 7}
+
+	t.Run("valid answer", func(t *testing.T) {
+		analyzer := newTestAnalyzer(fakeChatServer(t, http.StatusOK, " good.", 0))
+		got, err := analyzer.SuggestRanking(context.Background(), input, testRankings)
+		if err != nil {
+			t.Fatalf("SuggestRanking() error = %v", err)
+		}
+		if got != "Good" {
+			t.Errorf("SuggestRanking() = %q, want Good", got)
+		}
+	})
+
+	t.Run("non-200 response", func(t *testing.T) {
+		analyzer := newTestAnalyzer(fakeChatServer(t, http.StatusInternalServerError, "Good", 0))
+		if _, err := analyzer.SuggestRanking(context.Background(), input, testRankings); err == nil {
+			t.Fatal("SuggestRanking() error = nil, want provider error")
+		}
+	})
+
+	t.Run("answer outside the rankings", func(t *testing.T) {
+		analyzer := newTestAnalyzer(fakeChatServer(t, http.StatusOK, "Mediocre", 0))
+		if _, err := analyzer.SuggestRanking(context.Background(), input, testRankings); err == nil {
+			t.Fatal("SuggestRanking() error = nil, want invalid answer error")
+		}
+	})
+
+	t.Run("timeout falls back to local analyzer", func(t *testing.T) {
+		analyzer := FallbackReviewAnalyzer{
+			Primary:  newTestAnalyzer(fakeChatServer(t, http.StatusOK, "Terrible", time.Second)),
+			Fallback: LocalReviewAnalyzer{},
+			Timeout:  50 * time.Millisecond,
+		}
+		got, source, err := AnalyzeReview(context.Background(), analyzer, input, testRankings)
+		if err != nil {
+			t.Fatalf("AnalyzeReview() error = %v", err)
+		}
+		want, _ := LocalReviewAnalyzer{}.SuggestRanking(context.Background(), input, testRankings)
+		if got != want || source != "local" {
+			t.Errorf("AnalyzeReview() = %q from %q, want %q from local", got, source, want)
+		}
+	})
+}
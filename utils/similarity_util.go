@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"math"
+	"strings"
+)
+
+// SimilarityWeights controls how much each signal contributes to a similarity score
+type SimilarityWeights struct {
+	Genre    float64
+	Rating   float64
+	Keywords float64
+	Cast     float64
+}
+
+// SimilarityWeightsFromEnv reads SIMILAR_WEIGHT_* overrides
+func SimilarityWeightsFromEnv() SimilarityWeights {
+	return SimilarityWeights{
+		Genre:    GetEnvFloat("SIMILAR_WEIGHT_GENRE", 0.5),
+		Rating:   GetEnvFloat("SIMILAR_WEIGHT_RATING", 0.2),
+		Keywords: GetEnvFloat("SIMILAR_WEIGHT_KEYWORDS", 0.15),
+		Cast:     GetEnvFloat("SIMILAR_WEIGHT_CAST", 0.15),
+	}
+}
+
+// SimilarityFeatures are the signals of one movie. Empty slices and a nil rating mean "not present".
+type SimilarityFeatures struct {
+	GenreIDs []int
+	Rating   *float64 // 1-10
+	Keywords []string
+	Cast     []string
+}
+
+// Jaccard returns |a ∩ b| / |a ∪ b|, or 0 when both sets are empty
+func Jaccard[T comparable](a, b []T) float64 {
+	setA := map[T]bool{}
+	for _, item := range a {
+		setA[item] = true
+	}
+	setB := map[T]bool{}
+	for _, item := range b {
+		setB[item] = true
+	}
+
+	intersection := 0
+	for item := range setB {
+		if setA[item] {
+			intersection++
+		}
+	}
+	union := len(setA) + len(setB) - intersection
+	if union == 0 {
+		return 0
+	}
+	return float64(intersection) / float64(union)
+}
+
+// NormalizeKeywords lowercases and trims keywords so they compare case-insensitively
+func NormalizeKeywords(keywords []string) []string {
+	normalized := make([]string, 0, len(keywords))
+	for _, keyword := range keywords {
+		normalized = append(normalized, strings.ToLower(strings.TrimSpace(keyword)))
+	}
+	return normalized
+}
+
+// SimilarityScore combines genre overlap, rating proximity and shared keywords and cast into a
+// score between 0 and 1. A signal only counts when both movies have it, and the weights of the
+// signals that count are renormalized so sparse metadata is not penalized.
+func SimilarityScore(source, candidate SimilarityFeatures, weights SimilarityWeights) float64 {
+	var total, weightSum float64
+
+	if len(source.GenreIDs) > 0 && len(candidate.GenreIDs) > 0 {
+		total += weights.Genre * Jaccard(source.GenreIDs, candidate.GenreIDs)
+		weightSum += weights.Genre
+	}
+	if source.Rating != nil && candidate.Rating != nil {
+		total += weights.Rating * (1 - math.Abs(*source.Rating-*candidate.Rating)/9)
+		weightSum += weights.Rating
+	}
+	if len(source.Keywords) > 0 && len(candidate.Keywords) > 0 {
+		total += weights.Keywords * Jaccard(NormalizeKeywords(source.Keywords), NormalizeKeywords(candidate.Keywords))
+		weightSum += weights.Keywords
+	}
+	if len(source.Cast) > 0 && len(candidate.Cast) > 0 {
+		total += weights.Cast * Jaccard(source.Cast, candidate.Cast)
+		weightSum += weights.Cast
+	}
+
+	if weightSum == 0 {
+		return 0
+	}
+	return total / weightSum
+}
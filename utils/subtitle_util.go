@@ -0,0 +1,134 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Cue is a single timed caption
+type Cue struct {
+	Start float64
+	End   float64
+	Text  string
+}
+
+// SRT uses a comma before the milliseconds, WebVTT a dot; WebVTT may also omit the hours
+var cueTiming = regexp.MustCompile(`^((?:\d+:)?\d{2}:\d{2}[,.]\d{3})\s+-->\s+((?:\d+:)?\d{2}:\d{2}[,.]\d{3})`)
+
+// IsWebVTT reports whether a subtitle file carries the WebVTT signature
+func IsWebVTT(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), []byte("WEBVTT"))
+}
+
+// ParseSubtitles reads SRT or WebVTT cues and validates their timing: every cue must end after it
+// starts and cues must be in chronological order of their start time
+func ParseSubtitles(data []byte) ([]Cue, error) {
+	text := strings.TrimPrefix(string(data), "\ufeff")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	vtt := IsWebVTT(data)
+	var cues []Cue
+	for n, block := range strings.Split(text, "\n\n") {
+		lines := strings.Split(strings.Trim(block, "\n"), "\n")
+		if len(lines) == 0 || lines[0] == "" {
+			continue
+		}
+		// Skip the WebVTT header and NOTE/STYLE/REGION blocks
+		if vtt && (n == 0 || strings.HasPrefix(lines[0], "NOTE") || lines[0] == "STYLE" || lines[0] == "REGION") {
+			continue
+		}
+
+		// The line before the timing is an SRT counter or an optional WebVTT cue identifier
+		timingLine := 0
+		if !strings.Contains(lines[0], "-->") {
+			timingLine = 1
+		}
+		if timingLine >= len(lines) {
+			return nil, fmt.Errorf("cue %d: missing timing line", len(cues)+1)
+		}
+
+		match := cueTiming.FindStringSubmatch(strings.TrimSpace(lines[timingLine]))
+		if match == nil {
+			return nil, fmt.Errorf("cue %d: invalid timing line %q", len(cues)+1, lines[timingLine])
+		}
+		start, err := parseTimestamp(match[1])
+		if err != nil {
+			return nil, fmt.Errorf("cue %d: %v", len(cues)+1, err)
+		}
+		end, err := parseTimestamp(match[2])
+		if err != nil {
+			return nil, fmt.Errorf("cue %d: %v", len(cues)+1, err)
+		}
+		if end <= start {
+			return nil, fmt.Errorf("cue %d: ends at or before it starts", len(cues)+1)
+		}
+		if len(cues) > 0 && start < cues[len(cues)-1].Start {
+			return nil, fmt.Errorf("cue %d: starts before the previous cue", len(cues)+1)
+		}
+
+		cues = append(cues, Cue{Start: start, End: end, Text: strings.Join(lines[timingLine+1:], "\n")})
+	}
+
+	if len(cues) == 0 {
+		return nil, fmt.Errorf("no subtitle cues found")
+	}
+	return cues, nil
+}
+
+func parseTimestamp(value string) (float64, error) {
+	value = strings.Replace(value, ",", ".", 1)
+	parts := strings.Split(value, ":")
+	if len(parts) == 2 {
+		parts = append([]string{"0"}, parts...)
+	}
+
+	hours, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, fmt.Errorf("invalid timestamp %q", value)
+	}
+	minutes, err := strconv.Atoi(parts[1])
+	if err != nil || minutes > 59 {
+		return 0, fmt.Errorf("invalid timestamp %q", value)
+	}
+	seconds, err := strconv.ParseFloat(parts[2], 64)
+	if err != nil || seconds >= 60 {
+		return 0, fmt.Errorf("invalid timestamp %q", value)
+	}
+	return float64(hours*3600+minutes*60) + seconds, nil
+}
+
+func formatTimestamp(seconds float64) string {
+	millis := int64(math.Round(seconds * 1000))
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", millis/3600000, millis/60000%60, millis/1000%60, millis%1000)
+}
+
+// WriteWebVTT serializes cues as a WebVTT document
+func WriteWebVTT(cues []Cue) []byte {
+	var out bytes.Buffer
+	out.WriteString("WEBVTT\n\n")
+	for _, cue := range cues {
+		out.WriteString(formatTimestamp(cue.Start) + " --> " + formatTimestamp(cue.End) + "\n")
+		out.WriteString(cue.Text + "\n\n")
+	}
+	return out.Bytes()
+}
+
+// SubtitlePlaylist wraps a whole WebVTT file in a single-segment HLS media playlist
+func SubtitlePlaylist(vttURI string, duration float64) []byte {
+	target := int(math.Ceil(duration))
+	var out bytes.Buffer
+	out.WriteString("#EXTM3U\n")
+	out.WriteString("#EXT-X-VERSION:3\n")
+	out.WriteString("#EXT-X-TARGETDURATION:" + strconv.Itoa(target) + "\n")
+	out.WriteString("#EXT-X-MEDIA-SEQUENCE:0\n")
+	out.WriteString("#EXT-X-PLAYLIST-TYPE:VOD\n")
+	out.WriteString("#EXTINF:" + strconv.FormatFloat(duration, 'f', 3, 64) + ",\n")
+	out.WriteString(vttURI + "\n")
+	out.WriteString("#EXT-X-ENDLIST\n")
+	return out.Bytes()
+}
@@ -10,7 +10,6 @@ import (
 	"github.com/gin-gonic/gin"
 	jwt "github.com/golang-jwt/jwt/v5"
 	"go.mongodb.org/mongo-driver/v2/bson"
-	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
 type SignedDetails struct {
@@ -24,7 +23,6 @@ type SignedDetails struct {
 
 var SECRET_KEY string = getSecretKey()
 var REFRESH_SECRET_KEY string = getRefreshSecretKey()
-var userCollection *mongo.Collection = database.OpenCollection("User")
 
 func getSecretKey() string {
 	key := os.Getenv("SECRECT_KEY")
@@ -99,6 +97,7 @@ func UpdateAllTokens(userId, token, refreshToken string) (err error) {
 			"updated_at":    updateAt,
 		},
 	}
+	userCollection := database.OpenCollection("User")
 	_, err = userCollection.UpdateOne(ctx, bson.M{"user_id": userId}, updateData)
 
 	if err != nil {